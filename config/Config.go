@@ -8,6 +8,7 @@ import (
 	"os"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/spf13/viper"
 	"github.com/yatori-dev/yatori-go-core/models/ctype"
@@ -33,26 +34,233 @@ type BasicSetting struct {
 	LogModel       int    `json:"logModel" yaml:"logModel"`                                   //日志模式，0代表以视频提交学时基准打印日志，1代表以一个课程为基准打印信息，默认为0
 }
 type AiSetting struct {
+	AiType         ctype.AiType         `json:"aiType" yaml:"aiType"`
+	AiUrl          string               `json:"aiUrl" yaml:"aiUrl"`
+	Model          string               `json:"model"`
+	APIKEY         string               `json:"API_KEY" yaml:"API_KEY" mapstructure:"API_KEY"`
+	Providers      []AiProvider         `json:"providers,omitempty" yaml:"providers,omitempty"`           //额外的AI平台，开启共识模式后每道题会同时询问上面的AI和这里的所有AI
+	Consensus      int                  `json:"consensus,omitempty" yaml:"consensus,omitempty"`           //共识模式，0为关闭，1为开启，开启后按多数AI的答案作答
+	DisagreeReview int                  `json:"disagreeReview,omitempty" yaml:"disagreeReview,omitempty"` //共识模式下各AI答案不一致时的处理，0为直接采用多数答案，1为整份答卷留给人工审核
+	Prompts        map[string]PromptSet `json:"prompts,omitempty" yaml:"prompts,omitempty"`               //自定义提示词，key为平台类型(比如xuexitong)或default(所有平台)
+	Budget         AiBudget             `json:"budget,omitempty" yaml:"budget,omitempty"`                 //AI用量统计与上限
+	RateLimit      AiRateLimit          `json:"rateLimit,omitempty" yaml:"rateLimit,omitempty"`           //AI请求限流，所有平台和账号共用
+}
+
+// AiRateLimit AI请求限流，为0的项使用默认值
+type AiRateLimit struct {
+	MaxInFlight int `json:"maxInFlight,omitempty" yaml:"maxInFlight,omitempty"` //同时进行的请求数上限，默认1
+	PerMinute   int `json:"perMinute,omitempty" yaml:"perMinute,omitempty"`     //每分钟最多请求次数，默认不限制
+	MaxRetries  int `json:"maxRetries,omitempty" yaml:"maxRetries,omitempty"`   //被限流(429等)后最多重试次数，默认3，-1为不重试
+	BaseDelay   int `json:"baseDelay,omitempty" yaml:"baseDelay,omitempty"`     //平台没有给出等待时间时第一次重试前的等待，单位秒，默认10，之后每次翻倍
+	MaxDelay    int `json:"maxDelay,omitempty" yaml:"maxDelay,omitempty"`       //单次等待时间上限，单位秒，默认120
+}
+
+// AiBudget AI用量的价格与上限，token数按文字估算，为0的上限代表不限制
+type AiBudget struct {
+	InputPrice    float64 `json:"inputPrice,omitempty" yaml:"inputPrice,omitempty"`       //每千输入token价格，用于估算费用
+	OutputPrice   float64 `json:"outputPrice,omitempty" yaml:"outputPrice,omitempty"`     //每千输出token价格，用于估算费用
+	RunRequests   int     `json:"runRequests,omitempty" yaml:"runRequests,omitempty"`     //本次运行所有账号合计最多请求次数
+	RunTokens     int     `json:"runTokens,omitempty" yaml:"runTokens,omitempty"`         //本次运行所有账号合计最多token数
+	RunCost       float64 `json:"runCost,omitempty" yaml:"runCost,omitempty"`             //本次运行所有账号合计最多费用
+	DailyRequests int     `json:"dailyRequests,omitempty" yaml:"dailyRequests,omitempty"` //每个账号每天最多请求次数
+	DailyTokens   int     `json:"dailyTokens,omitempty" yaml:"dailyTokens,omitempty"`     //每个账号每天最多token数
+	DailyCost     float64 `json:"dailyCost,omitempty" yaml:"dailyCost,omitempty"`         //每个账号每天最多费用
+	OnExceed      int     `json:"onExceed,omitempty" yaml:"onExceed,omitempty"`           //超出上限后的处理，0为跳过AI改用其他答案来源，1为暂停答题(不保存不提交，留到下次运行)
+}
+
+// PromptSet 一组按题型区分的提示词模板，使用Go text/template语法，渲染结果替换内置提示词中的题目描述
+type PromptSet struct {
+	Choice string `json:"choice,omitempty" yaml:"choice,omitempty"` //单选、多选
+	Judge  string `json:"judge,omitempty" yaml:"judge,omitempty"`   //判断
+	Fill   string `json:"fill,omitempty" yaml:"fill,omitempty"`     //填空
+	Short  string `json:"short,omitempty" yaml:"short,omitempty"`   //简答
+	Term   string `json:"term,omitempty" yaml:"term,omitempty"`     //名词解释
+	Essay  string `json:"essay,omitempty" yaml:"essay,omitempty"`   //论述
+	BBS    string `json:"bbs,omitempty" yaml:"bbs,omitempty"`       //讨论回复
+}
+
+// get 返回种类对应的模板，种类见prompt包中的Kind常量
+func (p PromptSet) get(kind string) string {
+	switch kind {
+	case "choice":
+		return p.Choice
+	case "judge":
+		return p.Judge
+	case "fill":
+		return p.Fill
+	case "short":
+		return p.Short
+	case "term":
+		return p.Term
+	case "essay":
+		return p.Essay
+	case "bbs":
+		return p.BBS
+	}
+	return ""
+}
+
+// Prompt 返回平台和种类对应的提示词模板，平台单独设置优先于default，都没有时返回空
+func (a AiSetting) Prompt(platform, kind string) string {
+	for key, set := range a.Prompts {
+		if strings.EqualFold(key, platform) {
+			if text := set.get(kind); text != "" {
+				return text
+			}
+		}
+	}
+	for key, set := range a.Prompts {
+		if strings.EqualFold(key, "default") {
+			return set.get(kind)
+		}
+	}
+	return ""
+}
+
+// 单个AI平台
+type AiProvider struct {
 	AiType ctype.AiType `json:"aiType" yaml:"aiType"`
 	AiUrl  string       `json:"aiUrl" yaml:"aiUrl"`
 	Model  string       `json:"model"`
 	APIKEY string       `json:"API_KEY" yaml:"API_KEY" mapstructure:"API_KEY"`
 }
 
+// AllProviders 返回参与答题的所有AI平台，主AI排在最前
+func (a AiSetting) AllProviders() []AiProvider {
+	var list []AiProvider
+	if a.AiType != "" {
+		list = append(list, AiProvider{AiType: a.AiType, AiUrl: a.AiUrl, Model: a.Model, APIKEY: a.APIKEY})
+	}
+	return append(list, a.Providers...)
+}
+
+// Name AI平台显示名，比如TONGYI/qwen-plus
+func (a AiSetting) Name() string {
+	if a.Model == "" {
+		return string(a.AiType)
+	}
+	return string(a.AiType) + "/" + a.Model
+}
+
+// ConsensusOn 是否开启共识模式，至少需要两个AI平台
+func (a AiSetting) ConsensusOn() bool {
+	return a.Consensus == 1 && len(a.AllProviders()) >= 2
+}
+
 type ApiQueSetting struct {
 	Url string `json:"url"`
 }
 
+// 本地题库设置，AI和外挂题库的答案会缓存在本地，所有账号共用
+type LocalBankSetting struct {
+	Disable int    `json:"disable,omitempty" yaml:"disable,omitempty"` //是否关闭本地题库，0为开启，1为关闭，默认为0
+	Path    string `json:"path,omitempty" yaml:"path,omitempty"`       //题库文件位置，默认./assets/bank/question_bank.json
+}
+
+// 依赖检测设置，AI、外挂题库等依赖在启动时按账号需要统一检测一次
+type HealthSetting struct {
+	OnStartFail int `json:"onStartFail,omitempty" yaml:"onStartFail,omitempty"` //启动检测失败时的处理，0为停用答题继续刷视频等其他内容，1为直接退出，默认为0
+	Threshold   int `json:"threshold,omitempty" yaml:"threshold,omitempty"`     //运行中连续失败多少次视为故障并停用答题，默认3
+	Recheck     int `json:"recheck,omitempty" yaml:"recheck,omitempty"`         //故障后每隔多少分钟重新检测一次，恢复后继续答题，默认10，-1为不再检测
+}
+
+// 直播任务点设置，目前只支持学习通
+type LiveSetting struct {
+	Workers     int     `json:"workers,omitempty" yaml:"workers,omitempty"`         //同时观看的直播数，所有账号共用，默认2
+	PassPercent float64 `json:"passPercent,omitempty" yaml:"passPercent,omitempty"` //观看进度达到多少视为完成，默认90
+	Reschedule  int     `json:"reschedule,omitempty" yaml:"reschedule,omitempty"`   //未开播的直播加入待解锁队列，多少分钟后重新检测，默认10，-1为直接跳过
+	MaxWatch    int     `json:"maxWatch,omitempty" yaml:"maxWatch,omitempty"`       //单个直播最长观看多少分钟，超过后放弃并让出观看名额，默认240
+	Poll        int     `json:"poll,omitempty" yaml:"poll,omitempty"`               //观看时每隔多少秒提交一次进度，默认30
+}
+
+// WorkerCount 同时观看的直播数
+func (l LiveSetting) WorkerCount() int {
+	if l.Workers <= 0 {
+		return 2
+	}
+	return l.Workers
+}
+
+// Pass 直播观看进度的完成线
+func (l LiveSetting) Pass() float64 {
+	if l.PassPercent <= 0 || l.PassPercent > 100 {
+		return 90
+	}
+	return l.PassPercent
+}
+
+// RescheduleInterval 未开播的直播重新检测间隔，为0时直接跳过
+func (l LiveSetting) RescheduleInterval() time.Duration {
+	if l.Reschedule < 0 {
+		return 0
+	}
+	if l.Reschedule == 0 {
+		return 10 * time.Minute
+	}
+	return time.Duration(l.Reschedule) * time.Minute
+}
+
+// PollInterval 观看直播时提交进度的间隔
+func (l LiveSetting) PollInterval() time.Duration {
+	if l.Poll <= 0 {
+		return 30 * time.Second
+	}
+	return time.Duration(l.Poll) * time.Second
+}
+
+// WatchLimit 单个直播最长观看时间
+func (l LiveSetting) WatchLimit() time.Duration {
+	if l.MaxWatch <= 0 {
+		return 240 * time.Minute
+	}
+	return time.Duration(l.MaxWatch) * time.Minute
+}
+
+// 定时守护模式设置
+type ScheduleSetting struct {
+	Cron    []string `json:"cron,omitempty" yaml:"cron,omitempty"`       //cron表达式（分 时 日 月 周），到点自动开始刷课，比如"0 19 * * *"
+	Windows []string `json:"windows,omitempty" yaml:"windows,omitempty"` //允许学习的时间窗口，比如"08:00-23:00"，窗口外会在安全提交点暂停，不填则不限制
+}
+
+// 提交节奏设置，不填(为0)的项使用该平台默认值
+type PacingSetting struct {
+	Interval int `json:"interval,omitempty" yaml:"interval,omitempty"` //两次学时提交之间的基础间隔，单位秒
+	Jitter   int `json:"jitter,omitempty" yaml:"jitter,omitempty"`     //随机抖动范围，单位秒，实际间隔为interval±jitter
+	Step     int `json:"step,omitempty" yaml:"step,omitempty"`         //每次提交推进的学时，单位秒
+	Gap      int `json:"gap,omitempty" yaml:"gap,omitempty"`           //两个任务点之间的间隔，单位秒
+}
+
+// 失败重试设置，不填(为0)的项使用默认值
+type RetrySetting struct {
+	MaxAttempts int     `json:"maxAttempts,omitempty" yaml:"maxAttempts,omitempty"` //最大尝试次数，超过后该任务点标记为失败并跳过，-1为不限次数，默认6
+	BaseDelay   int     `json:"baseDelay,omitempty" yaml:"baseDelay,omitempty"`     //第一次重试前的等待时间，之后每次翻倍，单位秒，默认10
+	MaxDelay    int     `json:"maxDelay,omitempty" yaml:"maxDelay,omitempty"`       //单次等待时间上限，单位秒，默认300
+	Jitter      float64 `json:"jitter,omitempty" yaml:"jitter,omitempty"`           //随机抖动比例，0.2代表±20%，默认0.2
+}
+
+// 单个平台的设置
+type PlatformSetting struct {
+	Pacing     PacingSetting `json:"pacing,omitempty" yaml:"pacing,omitempty"`
+	Retry      RetrySetting  `json:"retry,omitempty" yaml:"retry,omitempty"`
+	MaxCourses int           `json:"maxConcurrentCourses,omitempty" yaml:"maxConcurrentCourses,omitempty"` //该平台所有账号合计同时学习的课程数，0为不限制，目前支持学习通
+}
+
 type Setting struct {
-	BasicSetting  BasicSetting  `json:"basicSetting" yaml:"basicSetting"`
-	EmailInform   EmailInform   `json:"emailInform" yaml:"emailInform"`
-	AiSetting     AiSetting     `json:"aiSetting" yaml:"aiSetting"`
-	ApiQueSetting ApiQueSetting `json:"apiQueSetting" yaml:"apiQueSetting"`
+	BasicSetting  BasicSetting               `json:"basicSetting" yaml:"basicSetting"`
+	EmailInform   EmailInform                `json:"emailInform" yaml:"emailInform"`
+	AiSetting     AiSetting                  `json:"aiSetting" yaml:"aiSetting"`
+	ApiQueSetting ApiQueSetting              `json:"apiQueSetting" yaml:"apiQueSetting"`
+	LocalBank     LocalBankSetting           `json:"localBank,omitempty" yaml:"localBank,omitempty"` //本地题库设置
+	Health        HealthSetting              `json:"health,omitempty" yaml:"health,omitempty"`       //依赖检测设置
+	Live          LiveSetting                `json:"live,omitempty" yaml:"live,omitempty"`           //直播任务点设置
+	Schedule      ScheduleSetting            `json:"schedule,omitempty" yaml:"schedule,omitempty"`   //全局定时设置，账号未单独配置时使用
+	Platforms     map[string]PlatformSetting `json:"platforms,omitempty" yaml:"platforms,omitempty"` //按平台区分的设置，key为平台类型，比如XUEXITONG
 }
 type CoursesSettings struct {
 	Name         string   `json:"name"`
 	IncludeExams []string `json:"includeExams" yaml:"includeExams"`
 	ExcludeExams []string `json:"excludeExams" yaml:"excludeExams"`
+	TargetScore  float64  `json:"targetScore,omitempty" yaml:"targetScore,omitempty"` //该课程单独的目标分数，不填则使用账号的targetScore
 }
 
 // 新增课程项结构体，支持课程ID和名称
@@ -62,20 +270,218 @@ type CourseItem struct {
 }
 
 type CoursesCustom struct {
-	VideoModel      int               `json:"videoModel" yaml:"videoModel"`         //观看视频模式
-	AutoExam        int               `json:"autoExam" yaml:"autoExam"`             //是否自动考试
-	ExamAutoSubmit  int               `json:"examAutoSubmit" yaml:"examAutoSubmit"` //是否自动提交试卷
-	ExcludeCourses  []interface{}     `json:"excludeCourses" yaml:"excludeCourses"` // 改为interface{}以兼容新旧格式
-	IncludeCourses  []interface{}     `json:"includeCourses" yaml:"includeCourses"` // 改为interface{}以兼容新旧格式
+	VideoModel      int               `json:"videoModel" yaml:"videoModel"`                                         //观看视频模式
+	MaxCourses      int               `json:"maxConcurrentCourses,omitempty" yaml:"maxConcurrentCourses,omitempty"` //多课程模式下该账号同时学习的课程数，默认3，-1为不限制
+	AutoExam        int               `json:"autoExam" yaml:"autoExam"`                                             //是否自动考试
+	ExamAutoSubmit  int               `json:"examAutoSubmit" yaml:"examAutoSubmit"`                                 //是否自动提交试卷，0只保存，1自动提交，2智能提交，3审核模式
+	AnswerChain     []string          `json:"answerChain,omitempty" yaml:"answerChain,omitempty"`                   //答案来源顺序，可选bank(本地题库)、external(外挂题库)、ai，前一个来源没有答案时使用下一个，不填则先查本地题库再按autoExam选择
+	ChainSimilarity float64           `json:"chainSimilarity,omitempty" yaml:"chainSimilarity,omitempty"`           //选择题答案与选项的最低相似度，低于该值视为没有答案并使用下一个来源，默认0.6
+	TargetScore     float64           `json:"targetScore,omitempty" yaml:"targetScore,omitempty"`                   //目标分数，自动提交后得分低于该值且平台还允许作答时自动重做，0为不重做
+	RetakeLimit     int               `json:"retakeLimit,omitempty" yaml:"retakeLimit,omitempty"`                   //未达到目标分数时最多重做几次，默认2
+	SmartSubmit     SmartSubmit       `json:"smartSubmit,omitempty" yaml:"smartSubmit,omitempty"`                   //智能提交的判定规则，examAutoSubmit为2时生效
+	Tasks           TaskSwitch        `json:"tasks,omitempty" yaml:"tasks,omitempty"`                               //按任务点类型单独开关
+	BBSReply        BBSReply          `json:"bbsReply,omitempty" yaml:"bbsReply,omitempty"`                         //讨论回复策略
+	ExcludeCourses  []interface{}     `json:"excludeCourses" yaml:"excludeCourses"`                                 // 改为interface{}以兼容新旧格式
+	IncludeCourses  []interface{}     `json:"includeCourses" yaml:"includeCourses"`                                 // 改为interface{}以兼容新旧格式
 	CoursesSettings []CoursesSettings `json:"coursesSettings" yaml:"coursesSettings"`
 }
+
+// 任务点类型
+const (
+	TaskVideo     = "video"     //视频
+	TaskDocument  = "document"  //文档
+	TaskHyperlink = "hyperlink" //外链
+	TaskLive      = "live"      //直播
+	TaskWork      = "work"      //章节作业
+	TaskExam      = "exam"      //考试
+	TaskBBS       = "bbs"       //讨论
+)
+
+// TaskSwitch 各类任务点的开关，0为默认，1为开启，-1为关闭，平台没有的类型忽略
+type TaskSwitch struct {
+	Video     int `json:"video,omitempty" yaml:"video,omitempty"`         //默认按videoModel，videoModel为0时开启也不刷
+	Document  int `json:"document,omitempty" yaml:"document,omitempty"`   //默认开启
+	Hyperlink int `json:"hyperlink,omitempty" yaml:"hyperlink,omitempty"` //默认开启
+	Live      int `json:"live,omitempty" yaml:"live,omitempty"`           //默认开启
+	Work      int `json:"work,omitempty" yaml:"work,omitempty"`           //默认按autoExam，autoExam为0时开启也不做
+	Exam      int `json:"exam,omitempty" yaml:"exam,omitempty"`           //默认按autoExam，autoExam为0时开启也不做
+	BBS       int `json:"bbs,omitempty" yaml:"bbs,omitempty"`             //讨论，默认autoExam不为0时开启，1为不论autoExam都回复，回复方式见bbsReply
+}
+
+// 讨论回复方式
+const (
+	BBSModeAI       = "ai"       //AI回复后直接发布
+	BBSModeTemplate = "template" //使用固定模板回复
+	BBSModeReview   = "review"   //回复写入待审核文件，人工确认后使用review apply发布
+)
+
+// BBSReply 讨论回复策略
+type BBSReply struct {
+	Mode      string   `json:"mode,omitempty" yaml:"mode,omitempty"`           //回复方式，ai、template、review，默认ai
+	Templates []string `json:"templates,omitempty" yaml:"templates,omitempty"` //固定回复模板，多个时随机选一个，review方式下配置了模板时用模板生成草稿
+	MaxLength int      `json:"maxLength,omitempty" yaml:"maxLength,omitempty"` //回复最多字数，超出时在句末截断，0为不限制
+	Tone      string   `json:"tone,omitempty" yaml:"tone,omitempty"`           //AI回复的语气，比如“口语化、像学生一样”
+}
+
+// BBSMode 讨论回复方式，未配置或无法识别时为ai
+func (c CoursesCustom) BBSMode() string {
+	switch mode := strings.ToLower(strings.TrimSpace(c.BBSReply.Mode)); mode {
+	case BBSModeTemplate, BBSModeReview:
+		return mode
+	}
+	return BBSModeAI
+}
+
+// BBSUseAI 讨论回复是否需要AI，模板回复以及配置了模板的审核方式不需要
+func (c CoursesCustom) BBSUseAI() bool {
+	switch c.BBSMode() {
+	case BBSModeTemplate:
+		return false
+	case BBSModeReview:
+		return len(c.BBSReply.Templates) == 0
+	}
+	return true
+}
+
+// TaskEnabled 是否处理该类型的任务点
+func (c CoursesCustom) TaskEnabled(task string) bool {
+	t := c.Tasks
+	switch task {
+	case TaskVideo:
+		return c.VideoModel != 0 && t.Video != -1
+	case TaskDocument:
+		return t.Document != -1
+	case TaskHyperlink:
+		return t.Hyperlink != -1
+	case TaskLive:
+		return t.Live != -1
+	case TaskWork:
+		return c.AutoExam != 0 && t.Work != -1
+	case TaskExam:
+		return c.AutoExam != 0 && t.Exam != -1
+	case TaskBBS:
+		if t.BBS != 0 {
+			return t.BBS == 1
+		}
+		return c.AutoExam != 0
+	}
+	return true
+}
+
+// SmartSubmit 智能提交的判定规则，有把握的题目占比达到要求时交卷，否则只保存
+type SmartSubmit struct {
+	MinRatio      float64                    `json:"minRatio,omitempty" yaml:"minRatio,omitempty"`           //有把握的题目至少占比(0~1)，默认1即每道题都要有把握
+	MinSimilarity float64                    `json:"minSimilarity,omitempty" yaml:"minSimilarity,omitempty"` //选择、判断题答案与选项的最低相似度，达到才算有把握，默认0.9
+	Types         map[string]SmartSubmitRule `json:"types,omitempty" yaml:"types,omitempty"`                 //按题型单独设置，key为题型，比如单选、填空、简答
+}
+
+// SmartSubmitRule 单个题型的智能提交规则
+type SmartSubmitRule struct {
+	MinSimilarity float64 `json:"minSimilarity,omitempty" yaml:"minSimilarity,omitempty"` //该题型的最低相似度，不填使用smartSubmit.minSimilarity
+	Required      int     `json:"required,omitempty" yaml:"required,omitempty"`           //1为该题型每道题都必须有把握，不受minRatio影响
+	Ignore        int     `json:"ignore,omitempty" yaml:"ignore,omitempty"`               //1为该题型不参与判定，比如允许简答题留空
+}
+
+// Rule 返回题型对应的规则，相似度未单独设置时使用全局值
+func (s SmartSubmit) Rule(qType string) SmartSubmitRule {
+	var rule SmartSubmitRule
+	trim := func(t string) string { return strings.TrimSuffix(strings.TrimSpace(t), "题") }
+	for key, r := range s.Types { //“单选”和“单选题”视为同一种
+		if trim(key) == trim(qType) {
+			rule = r
+			break
+		}
+	}
+	if rule.MinSimilarity <= 0 {
+		rule.MinSimilarity = s.MinSimilarity
+	}
+	if rule.MinSimilarity <= 0 {
+		rule.MinSimilarity = 0.9
+	}
+	return rule
+}
+
+// Ratio 返回有把握的题目最低占比
+func (s SmartSubmit) Ratio() float64 {
+	if s.MinRatio <= 0 || s.MinRatio > 1 {
+		return 1
+	}
+	return s.MinRatio
+}
+
+// 答案来源
+const (
+	AnswerBank     = "bank"     //本地题库
+	AnswerExternal = "external" //外挂题库
+	AnswerAI       = "ai"       //AI
+)
+
+// AnswerSources 返回答案来源顺序，没有配置answerChain时先查本地题库再按autoExam选择
+func (c CoursesCustom) AnswerSources() []string {
+	var sources []string
+	for _, source := range c.AnswerChain {
+		source = strings.ToLower(strings.TrimSpace(source))
+		if source == AnswerBank || source == AnswerExternal || source == AnswerAI {
+			sources = append(sources, source)
+		}
+	}
+	if len(sources) > 0 {
+		return sources
+	}
+	switch c.AutoExam {
+	case 1:
+		return []string{AnswerBank, AnswerAI}
+	case 2:
+		return []string{AnswerBank, AnswerExternal}
+	}
+	return []string{AnswerBank}
+}
+
+// MinSimilarity 选择题答案与选项的最低相似度
+func (c CoursesCustom) MinSimilarity() float64 {
+	if c.ChainSimilarity <= 0 {
+		return 0.6
+	}
+	return c.ChainSimilarity
+}
+
+// GetTargetScore 返回课程的目标分数，课程单独设置优先
+func (c CoursesCustom) GetTargetScore(courseName string) float64 {
+	for _, course := range c.CoursesSettings {
+		if course.Name == courseName && course.TargetScore > 0 {
+			return course.TargetScore
+		}
+	}
+	return c.TargetScore
+}
+
+// GetRetakeLimit 返回最多重做次数
+func (c CoursesCustom) GetRetakeLimit() int {
+	if c.RetakeLimit <= 0 {
+		return 2
+	}
+	return c.RetakeLimit
+}
+
+// CourseLimit 多课程模式下同时学习的课程数，0为不限制
+func (c CoursesCustom) CourseLimit() int {
+	if c.MaxCourses < 0 {
+		return 0
+	}
+	if c.MaxCourses == 0 {
+		return 3
+	}
+	return c.MaxCourses
+}
+
 type Users struct {
-	AccountType   string        `json:"accountType" yaml:"accountType"`
-	URL           string        `json:"url"`
-	Account       string        `json:"account"`
-	Password      string        `json:"password"`
-	IsProxy       int           `json:"isProxy" yaml:"isProxy"` //是否代理IP
-	CoursesCustom CoursesCustom `json:"coursesCustom" yaml:"coursesCustom"`
+	AccountType   string          `json:"accountType" yaml:"accountType"`
+	URL           string          `json:"url"`
+	Account       string          `json:"account"`
+	Password      string          `json:"password"`
+	IsProxy       int             `json:"isProxy" yaml:"isProxy"` //是否代理IP
+	CoursesCustom CoursesCustom   `json:"coursesCustom" yaml:"coursesCustom"`
+	Schedule      ScheduleSetting `json:"schedule,omitempty" yaml:"schedule,omitempty"` //账号单独的定时设置，不填则使用全局设置
 }
 
 // 读取json配置文件
@@ -245,6 +651,18 @@ func CmpCourse(courseName, courseId string, courseList []interface{}) bool {
     return false
 }
 
+// GetSchedule 获取账号实际生效的定时设置，账号未配置的部分使用全局设置
+func (user *Users) GetSchedule(setting Setting) ScheduleSetting {
+	schedule := user.Schedule
+	if len(schedule.Cron) == 0 {
+		schedule.Cron = setting.Schedule.Cron
+	}
+	if len(schedule.Windows) == 0 {
+		schedule.Windows = setting.Schedule.Windows
+	}
+	return schedule
+}
+
 func GetUserInput(prompt string) string {
 	reader := bufio.NewReader(os.Stdin)
 	fmt.Print(prompt)
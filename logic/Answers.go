@@ -0,0 +1,50 @@
+package logic
+
+import (
+	"flag"
+	"strconv"
+	"strings"
+	"yatori-go-console/utils/audit"
+
+	lg "github.com/yatori-dev/yatori-go-core/utils/log"
+)
+
+// Answers 答题记录查询命令，查看每道题提交了什么答案、答案从哪来以及是否答对
+//
+//	answers [-account 账号] [-course 课程] [-title 作业或考试] [-source 来源] [-wrong] [-limit 条数]
+func Answers(args []string) {
+	flags := flag.NewFlagSet("answers", flag.ExitOnError)
+	var filter audit.Filter
+	flags.StringVar(&filter.Account, "account", "", "只看该账号")
+	flags.StringVar(&filter.Course, "course", "", "课程名称包含该内容")
+	flags.StringVar(&filter.Title, "title", "", "作业或考试名称包含该内容")
+	flags.StringVar(&filter.Source, "source", "", "答案来源，比如ai、external、bank、review")
+	flags.BoolVar(&filter.Wrong, "wrong", false, "只看批改为错误的题")
+	limit := flags.Int("limit", 50, "最多显示最近多少条，0为全部")
+	flags.Parse(args)
+
+	list, err := audit.Query(filter)
+	if err != nil {
+		lg.Print(lg.INFO, lg.BoldRed, "读取答题记录失败：", err.Error())
+		return
+	}
+	total := len(list)
+	if *limit > 0 && len(list) > *limit {
+		list = list[len(list)-*limit:]
+	}
+	for _, record := range list {
+		color, correct := lg.Default, "未批改"
+		if record.Correct != nil && *record.Correct {
+			color, correct = lg.Green, "正确"
+		} else if record.Correct != nil {
+			color, correct = lg.BoldRed, "错误"
+		}
+		similarity := ""
+		if record.Similarity > 0 {
+			similarity = " 相似度" + strconv.FormatFloat(record.Similarity, 'f', 2, 64)
+		}
+		lg.Print(lg.INFO, "[", lg.Green, record.Account, lg.Default, "] ", "【", record.Course, "】", "【", record.Title, "】 ", record.Time.Format("2006-01-02 15:04"), " <", record.Source, "> ",
+			record.Type, "：", record.Content, lg.Yellow, " 答案：", strings.Join(record.Answers, " | "), lg.Default, similarity, " ", color, correct)
+	}
+	lg.Print(lg.INFO, lg.Purple, "Yatori --- ", "符合条件的答题记录共 ", strconv.Itoa(total), " 条，显示 ", strconv.Itoa(len(list)), " 条")
+}
@@ -0,0 +1,51 @@
+package logic
+
+import (
+	"os"
+	"strconv"
+	"yatori-go-console/config"
+	"yatori-go-console/utils/qbank"
+
+	lg "github.com/yatori-dev/yatori-go-core/utils/log"
+)
+
+// Bank 本地题库管理命令
+//
+//	bank import <文件...>  从json、csv、xlsx、txt文件导入题目，相同或相似的题只保留一份
+//	bank export <文件>     将本地题库导出为json、csv、xlsx、txt文件，格式由后缀决定
+func Bank(args []string) {
+	if fileExists("./config.yaml") {
+		configJson := config.ReadConfig("./config.yaml")
+		qbank.SetPath(configJson.Setting.LocalBank.Path)
+	}
+	if len(args) < 2 {
+		lg.Print(lg.INFO, lg.BoldRed, "用法：bank import <文件...> 或 bank export <文件>")
+		os.Exit(0)
+	}
+	switch args[0] {
+	case "import":
+		for _, path := range args[1:] {
+			list, err := qbank.ReadFile(path)
+			if err != nil {
+				lg.Print(lg.INFO, "[", lg.Green, path, lg.Default, "] ", lg.BoldRed, "读取题库文件失败：", err.Error())
+				continue
+			}
+			added, duplicated, err := qbank.Import(list, qbank.SourceImport)
+			if err != nil {
+				lg.Print(lg.INFO, "[", lg.Green, path, lg.Default, "] ", lg.BoldRed, "导入题库失败：", err.Error())
+				continue
+			}
+			lg.Print(lg.INFO, "[", lg.Green, path, lg.Default, "] ", lg.Green, "导入完毕，共读取 ", strconv.Itoa(len(list)), " 题，新增 ", strconv.Itoa(added), " 题，重复 ", strconv.Itoa(duplicated), " 题")
+		}
+		lg.Print(lg.INFO, lg.Green, "Yatori --- ", "本地题库现有 ", strconv.Itoa(qbank.Count()), " 题")
+	case "export":
+		count, err := qbank.Export(args[1])
+		if err != nil {
+			lg.Print(lg.INFO, "[", lg.Green, args[1], lg.Default, "] ", lg.BoldRed, "导出题库失败：", err.Error())
+			os.Exit(0)
+		}
+		lg.Print(lg.INFO, "[", lg.Green, args[1], lg.Default, "] ", lg.Green, "导出完毕，共 ", strconv.Itoa(count), " 题")
+	default:
+		lg.Print(lg.INFO, lg.BoldRed, "未知的题库命令：", args[0], "，可用命令为 import、export")
+	}
+}
@@ -0,0 +1,200 @@
+package logic
+
+import (
+	"os"
+	"sync"
+	"time"
+	"yatori-go-console/config"
+	"yatori-go-console/utils/qbank"
+	"yatori-go-console/utils/schedule"
+
+	lg "github.com/yatori-dev/yatori-go-core/utils/log"
+)
+
+// 守护模式下单个账号的定时任务
+type daemonJob struct {
+	user    config.Users
+	crons   []*schedule.Cron
+	next    time.Time //下一次触发时间
+	running bool      //是否正在运行或排队等待运行
+	started bool      //是否已经开始运行
+}
+
+// daemonBatch 一批同时触发的账号
+type daemonBatch struct {
+	configData config.JSONDataForConfig
+	jobs       []*daemonJob
+}
+
+var daemonMut sync.Mutex //守护任务状态锁
+
+// Daemon 守护模式，按照cron表达式定时自动刷课
+func Daemon() {
+	configJson := loadConfig()
+
+	var jobs []*daemonJob
+	hasCron := false
+	for _, user := range configJson.Users {
+		job := &daemonJob{user: user}
+		for _, expr := range user.GetSchedule(configJson.Setting).Cron {
+			cron, err := schedule.ParseCron(expr)
+			if err != nil {
+				lg.Print(lg.INFO, "[", lg.Green, user.Account, lg.Default, "] ", lg.BoldRed, "定时配置错误：", err.Error())
+				os.Exit(0)
+			}
+			job.crons = append(job.crons, cron)
+		}
+		//没有配置定时的账号也需要守护，运行中新加入的待解锁任务到时间后同样会处理
+		if len(job.crons) == 0 {
+			lg.Print(lg.INFO, "[", lg.Green, user.Account, lg.Default, "] ", lg.Yellow, "该账号未配置定时任务，守护模式下只处理待解锁任务")
+		} else {
+			hasCron = true
+		}
+		job.next = nextFire(job.crons, time.Now())
+		jobs = append(jobs, job)
+	}
+	//既没有定时也没有待解锁任务和未完成的断点时，守护模式不会做任何事
+	if !hasCron && !hasPendingOrResume(jobs) {
+		lg.Print(lg.INFO, lg.BoldRed, "守护模式需要在setting.schedule或账号schedule中配置cron表达式")
+		os.Exit(0)
+	}
+	lg.Print(lg.INFO, lg.Purple, "Yatori --- ", "已进入守护模式")
+	//各平台的刷课模块使用包级别的锁，多批同时运行会互相等待，所以逐批运行
+	//每个账号同一时间最多在一个批次中，队列长度不会超过账号数
+	queue := make(chan daemonBatch, len(jobs))
+	go runBatches(queue)
+
+	//上一次未完成的账号从断点继续
+	checkpoints := schedule.LoadCheckpoints()
+	var resumeJobs []*daemonJob
+	for _, job := range jobs {
+		if cp, ok := checkpoints[job.user.Account]; ok && !cp.Finished && !cp.LastStart.IsZero() {
+			lg.Print(lg.INFO, "[", lg.Green, job.user.Account, lg.Default, "] ", lg.Yellow, "检测到上次运行未完成，将从断点继续")
+			resumeJobs = append(resumeJobs, job)
+		}
+	}
+	startJobs(queue, &configJson, resumeJobs)
+	printDaemonStatus(jobs)
+
+	for {
+		//每分钟检查一次定时任务和待解锁队列
+		now := time.Now()
+		time.Sleep(time.Until(now.Truncate(time.Minute).Add(time.Minute)))
+		now = time.Now()
+
+		var dueJobs []*daemonJob
+		for _, job := range jobs {
+			cronDue := !job.next.IsZero() && !job.next.After(now)
+			unlock := schedule.NextPending(job.user.Account)
+			unlockDue := !unlock.IsZero() && !unlock.After(now)
+			if !cronDue && !unlockDue {
+				continue
+			}
+			if cronDue {
+				job.next = nextFire(job.crons, now)
+			}
+			daemonMut.Lock()
+			running := job.running
+			daemonMut.Unlock()
+			if running {
+				//解锁任务等这一轮结束后再处理，只有定时触发才提示
+				if cronDue {
+					lg.Print(lg.INFO, "[", lg.Green, job.user.Account, lg.Default, "] ", lg.Yellow, "上一次任务还未结束，本次触发已跳过")
+				}
+				continue
+			}
+			if !cronDue {
+				lg.Print(lg.INFO, "[", lg.Green, job.user.Account, lg.Default, "] ", lg.Green, "有任务已到解锁时间，开始学习")
+			}
+			dueJobs = append(dueJobs, job)
+		}
+		if len(dueJobs) != 0 {
+			startJobs(queue, &configJson, dueJobs)
+			printDaemonStatus(jobs)
+		}
+	}
+}
+
+// startJobs 将一批账号加入运行队列，上一批结束后开始刷课
+func startJobs(queue chan<- daemonBatch, configData *config.JSONDataForConfig, jobs []*daemonJob) {
+	if len(jobs) == 0 {
+		return
+	}
+	batch := daemonBatch{configData: config.JSONDataForConfig{Setting: configData.Setting}, jobs: jobs}
+	daemonMut.Lock()
+	for _, job := range jobs {
+		job.running = true
+		batch.configData.Users = append(batch.configData.Users, job.user)
+	}
+	daemonMut.Unlock()
+	queue <- batch
+}
+
+// runBatches 逐批刷课，上一批全部结束后才开始下一批
+func runBatches(queue <-chan daemonBatch) {
+	for batch := range queue {
+		daemonMut.Lock()
+		for _, job := range batch.jobs {
+			job.started = true
+			schedule.MarkStart(job.user.Account)
+			schedule.ClearDuePending(job.user.Account, time.Now()) //本轮仍未解锁的会重新加入队列
+		}
+		daemonMut.Unlock()
+		brushBlock(&batch.configData)
+		daemonMut.Lock()
+		for _, job := range batch.jobs {
+			job.running, job.started = false, false
+			schedule.MarkFinish(job.user.Account)
+		}
+		daemonMut.Unlock()
+		qbank.Flush() //守护进程不会退出，每轮结束把题库写回文件
+		lg.Print(lg.INFO, lg.Purple, "Yatori --- ", "本轮定时任务执行完毕")
+		printUsage()
+	}
+}
+
+// hasPendingOrResume 是否有账号存在待解锁任务或上一次未完成的断点
+func hasPendingOrResume(jobs []*daemonJob) bool {
+	checkpoints := schedule.LoadCheckpoints()
+	for _, job := range jobs {
+		if !schedule.NextPending(job.user.Account).IsZero() {
+			return true
+		}
+		if cp, ok := checkpoints[job.user.Account]; ok && !cp.Finished && !cp.LastStart.IsZero() {
+			return true
+		}
+	}
+	return false
+}
+
+// nextFire 获取多个cron表达式中最近的一次触发时间
+func nextFire(crons []*schedule.Cron, t time.Time) time.Time {
+	var next time.Time
+	for _, cron := range crons {
+		n := cron.Next(t)
+		if !n.IsZero() && (next.IsZero() || n.Before(next)) {
+			next = n
+		}
+	}
+	return next
+}
+
+// printDaemonStatus 打印守护模式下各账号状态以及待解锁队列
+func printDaemonStatus(jobs []*daemonJob) {
+	daemonMut.Lock()
+	for _, job := range jobs {
+		next := "无"
+		if !job.next.IsZero() {
+			next = job.next.Format("2006-01-02 15:04")
+		}
+		if job.started {
+			lg.Print(lg.INFO, "[", lg.Green, job.user.Account, lg.Default, "] ", lg.Green, "运行中", lg.Default, "，下一次触发：", next)
+		} else if job.running {
+			lg.Print(lg.INFO, "[", lg.Green, job.user.Account, lg.Default, "] ", lg.Yellow, "排队中", lg.Default, "，等待上一批结束，下一次触发：", next)
+		} else {
+			lg.Print(lg.INFO, "[", lg.Green, job.user.Account, lg.Default, "] ", lg.DarkGray, "等待中", lg.Default, "，下一次触发：", next)
+		}
+	}
+	daemonMut.Unlock()
+	printPending()
+}
@@ -2,11 +2,19 @@ package logic
 
 import (
 	"os"
+	"strconv"
 	"strings"
 	"sync"
+	"time"
 	"yatori-go-console/config"
 	"yatori-go-console/logic/yinghua"
 	utils2 "yatori-go-console/utils"
+	"yatori-go-console/utils/ailimit"
+	"yatori-go-console/utils/aiusage"
+	"yatori-go-console/utils/pacing"
+	"yatori-go-console/utils/qbank"
+	"yatori-go-console/utils/retry"
+	"yatori-go-console/utils/schedule"
 
 	lg "github.com/yatori-dev/yatori-go-core/utils/log"
 	"gopkg.in/yaml.v3"
@@ -21,7 +29,32 @@ func fileExists(fileName string) bool {
 }
 
 func Lunch() {
+	configJson := loadConfig()
+	brushBlock(&configJson)
+	qbank.Flush()
+	lg.Print(lg.INFO, lg.Red, "Yatori --- ", "所有任务执行完毕")
+	printPending()
+	printUsage()
+}
 
+// printPending 打印待解锁队列
+func printPending() {
+	items := schedule.LoadPending()
+	if len(items) == 0 {
+		return
+	}
+	lg.Print(lg.INFO, lg.Purple, "Yatori --- ", "待解锁队列（共 ", strconv.Itoa(len(items)), " 个，守护模式下会在解锁后自动学习）：")
+	for _, item := range items {
+		node := ""
+		if item.Node != "" {
+			node = "【" + item.Node + "】"
+		}
+		lg.Print(lg.INFO, "[", lg.Green, item.Account, lg.Default, "] ", "【", item.Course, "】", node, " >>> ", lg.Yellow, item.Until.Format("2006-01-02 15:04"), " 解锁")
+	}
+}
+
+// loadConfig 读取(或引导生成)配置文件并完成日志、配置检查、代理池等初始化
+func loadConfig() config.JSONDataForConfig {
 	// 检查config.yaml是否存在
 	if !fileExists("./config.yaml") {
 		// 不存在使用生成方式建立
@@ -98,11 +131,23 @@ func Lunch() {
 	configJsonCheck(&configJson)
 	//是否开启IP代理池
 	checkProxyIp()
+	//学习时间窗口
+	applyWindows(&configJson)
+	//各平台提交节奏
+	applyPacing(&configJson)
+	//各平台失败重试策略
+	applyRetry(&configJson)
+	//本地题库
+	applyLocalBank(&configJson)
+	//AI用量上限
+	applyBudget(&configJson)
+	//AI请求限流
+	applyAiLimit(&configJson)
+	//依赖检测
+	preflight(&configJson)
 
 	//isIpProxy(&configJson)
-
-	brushBlock(&configJson)
-	lg.Print(lg.INFO, lg.Red, "Yatori --- ", "所有任务执行完毕")
+	return configJson
 }
 
 var platformLock sync.WaitGroup //平台锁
@@ -149,6 +194,73 @@ func configJsonCheck(configData *config.JSONDataForConfig) {
 	}
 }
 
+// applyWindows 注册各账号允许学习的时间窗口
+func applyWindows(configData *config.JSONDataForConfig) {
+	for _, user := range configData.Users {
+		windows, err := schedule.ParseWindows(user.GetSchedule(configData.Setting).Windows)
+		if err != nil {
+			lg.Print(lg.INFO, "[", lg.Green, user.Account, lg.Default, "] ", lg.BoldRed, "学习时间窗口配置错误：", err.Error())
+			os.Exit(0)
+		}
+		schedule.SetWindows(user.Account, windows)
+	}
+}
+
+// applyPacing 注册各平台的提交节奏
+func applyPacing(configData *config.JSONDataForConfig) {
+	for name, platform := range configData.Setting.Platforms {
+		p := platform.Pacing
+		pacing.Set(name, pacing.Pacing{Interval: p.Interval, Jitter: p.Jitter, Step: p.Step, Gap: p.Gap})
+	}
+}
+
+// applyRetry 注册各平台的失败重试策略
+func applyRetry(configData *config.JSONDataForConfig) {
+	for name, platform := range configData.Setting.Platforms {
+		r := platform.Retry
+		retry.Set(name, retry.Policy{
+			MaxAttempts: r.MaxAttempts,
+			BaseDelay:   time.Duration(r.BaseDelay) * time.Second,
+			MaxDelay:    time.Duration(r.MaxDelay) * time.Second,
+			Jitter:      r.Jitter,
+		})
+	}
+}
+
+// applyLocalBank 设置本地题库
+func applyLocalBank(configData *config.JSONDataForConfig) {
+	qbank.SetPath(configData.Setting.LocalBank.Path)
+	qbank.SetEnabled(configData.Setting.LocalBank.Disable != 1)
+}
+
+// applyBudget 设置AI用量的价格与上限
+func applyBudget(configData *config.JSONDataForConfig) {
+	b := configData.Setting.AiSetting.Budget
+	aiusage.SetLimits(aiusage.Limits{
+		InputPrice:    b.InputPrice,
+		OutputPrice:   b.OutputPrice,
+		RunRequests:   b.RunRequests,
+		RunTokens:     b.RunTokens,
+		RunCost:       b.RunCost,
+		DailyRequests: b.DailyRequests,
+		DailyTokens:   b.DailyTokens,
+		DailyCost:     b.DailyCost,
+		Pause:         b.OnExceed == 1,
+	})
+}
+
+// applyAiLimit 设置AI请求限流
+func applyAiLimit(configData *config.JSONDataForConfig) {
+	r := configData.Setting.AiSetting.RateLimit
+	ailimit.Set(ailimit.Config{
+		MaxInFlight: r.MaxInFlight,
+		PerMinute:   r.PerMinute,
+		MaxRetries:  r.MaxRetries,
+		BaseDelay:   time.Duration(r.BaseDelay) * time.Second,
+		MaxDelay:    time.Duration(r.MaxDelay) * time.Second,
+	})
+}
+
 // 检查代理IP是否为正常
 func checkProxyIp() {
 	if !utils2.IsProxyFlag {
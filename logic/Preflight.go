@@ -0,0 +1,76 @@
+package logic
+
+import (
+	"os"
+	"strconv"
+	"time"
+	"yatori-go-console/config"
+	utils2 "yatori-go-console/utils"
+	"yatori-go-console/utils/ailimit"
+	"yatori-go-console/utils/health"
+
+	"github.com/yatori-dev/yatori-go-core/que-core/external"
+	lg "github.com/yatori-dev/yatori-go-core/utils/log"
+)
+
+// preflight 启动时按各账号的需要统一检测一次依赖，结果缓存在health中，
+// AI和外挂题库不可用时按配置直接退出或停用答题继续学习其他内容，邮件服务和提示音不可用时只关闭对应功能
+func preflight(configData *config.JSONDataForConfig) {
+	setting := &configData.Setting
+	health.SetPolicy(health.Policy{Threshold: setting.Health.Threshold, Recheck: time.Duration(setting.Health.Recheck) * time.Minute})
+	needs := map[string]bool{}
+	for _, user := range configData.Users {
+		if user.AccountType == "XUEXITONG" && user.CoursesCustom.TaskEnabled(config.TaskBBS) && user.CoursesCustom.BBSUseAI() { //学习通讨论由AI回复
+			needs[health.AI] = true
+		}
+		if user.CoursesCustom.AutoExam == 0 {
+			continue
+		}
+		for _, source := range user.CoursesCustom.AnswerSources() {
+			needs[source] = true
+		}
+	}
+	checks := []struct {
+		name  string
+		probe func() error
+	}{
+		{health.AI, func() error {
+			return ailimit.Check(setting.AiSetting.AiUrl, setting.AiSetting.Model, setting.AiSetting.APIKEY, setting.AiSetting.AiType)
+		}},
+		{health.External, func() error {
+			return external.CheckApiQueRequest(setting.ApiQueSetting.Url, 3, nil)
+		}},
+	}
+	for _, check := range checks {
+		if !needs[check.name] {
+			continue
+		}
+		lg.Print(lg.INFO, lg.Yellow, "正在检测", health.Name(check.name), "可用性...")
+		if err := health.Check(check.name, check.probe); err != nil {
+			lg.Print(lg.INFO, lg.BoldRed, health.Name(check.name), "不可用，错误信息：", err.Error())
+			if setting.Health.OnStartFail == 1 {
+				os.Exit(0)
+			}
+			lg.Print(lg.INFO, lg.BoldRed, "使用", health.Name(check.name), "的账号将暂停答题，继续学习其他内容")
+		}
+	}
+	if mail := setting.EmailInform; mail.Sw == 1 {
+		err := health.Check(health.SMTP, func() error {
+			port, err := strconv.Atoi(mail.SMTPPort)
+			if err != nil {
+				return err
+			}
+			return utils2.CheckMail(mail.SMTPHost, port, mail.Email, mail.Password)
+		})
+		if err != nil {
+			lg.Print(lg.INFO, lg.BoldRed, health.Name(health.SMTP), "不可用，已关闭邮件通知，错误信息：", err.Error())
+			setting.EmailInform.Sw = 0
+		}
+	}
+	if setting.BasicSetting.CompletionTone == 1 {
+		if err := health.Check(health.Sound, utils2.CheckNoticeSound); err != nil {
+			lg.Print(lg.INFO, lg.BoldRed, health.Name(health.Sound), "无法播放，已关闭完成提示音，错误信息：", err.Error())
+			setting.BasicSetting.CompletionTone = 0
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package logic
+
+import (
+	"os"
+	"strconv"
+	"strings"
+	"yatori-go-console/config"
+	"yatori-go-console/logic/xuexitong"
+	"yatori-go-console/logic/yinghua"
+	"yatori-go-console/utils/review"
+
+	lg "github.com/yatori-dev/yatori-go-core/utils/log"
+)
+
+// Review 答卷审核命令，examAutoSubmit为3时生成的待审核答卷以及bbsReply为review时生成的讨论回复在这里查看和提交
+//
+//	review list             列出所有待审核答卷
+//	review apply [文件...]   提交人工确认后的答卷，不指定文件则提交全部待审核答卷
+func Review(args []string) {
+	action := "list"
+	if len(args) > 0 {
+		action = args[0]
+	}
+	switch action {
+	case "list":
+		count := 0
+		for _, item := range review.List() {
+			if item.Status != review.StatusPending {
+				continue
+			}
+			count++
+			lg.Print(lg.INFO, "[", lg.Green, item.Account, lg.Default, "] ", "<", item.Platform, "> ", "【", item.Course, "】", "【", item.Title, "】 ", lg.Yellow, item.Path)
+		}
+		lg.Print(lg.INFO, lg.Purple, "Yatori --- ", "待审核答卷共 ", strconv.Itoa(count), " 份，修改文件中的答案后使用 review apply 提交")
+	case "apply":
+		configJson := config.ReadConfig("./config.yaml")
+		configJsonCheck(&configJson)
+		var items []review.Item
+		if len(args) > 1 {
+			for _, path := range args[1:] {
+				item, err := review.Load(path)
+				if err != nil {
+					lg.Print(lg.INFO, "[", lg.Green, path, lg.Default, "] ", lg.BoldRed, "读取待审核文件失败：", err.Error())
+					continue
+				}
+				items = append(items, item)
+			}
+		} else {
+			items = review.List()
+		}
+		//按账号分组，每个账号只登录一次
+		grouped := map[string][]review.Item{}
+		for _, item := range items {
+			if item.Status != review.StatusPending {
+				continue
+			}
+			grouped[item.Platform+"|"+item.Account] = append(grouped[item.Platform+"|"+item.Account], item)
+		}
+		for key, list := range grouped {
+			user, ok := findUser(configJson.Users, list[0].Platform, list[0].Account)
+			if !ok {
+				lg.Print(lg.INFO, "[", lg.Green, list[0].Account, lg.Default, "] ", lg.BoldRed, "配置文件中没有找到该账号，相关答卷未提交：", key)
+				continue
+			}
+			switch strings.ToUpper(user.AccountType) {
+			case "YINGHUA":
+				yinghua.ApplyReview(user, list)
+			case "XUEXITONG":
+				xuexitong.ApplyReview(user, list)
+			default:
+				lg.Print(lg.INFO, "[", lg.Green, user.Account, lg.Default, "] ", lg.BoldRed, "该平台暂不支持审核提交：", user.AccountType)
+			}
+		}
+	default:
+		lg.Print(lg.INFO, lg.BoldRed, "未知的审核命令：", action, "，可用命令为 list、apply")
+		os.Exit(0)
+	}
+}
+
+// findUser 按平台和账号查找配置中的用户
+func findUser(users []config.Users, platform, account string) (config.Users, bool) {
+	for _, user := range users {
+		if strings.EqualFold(user.AccountType, platform) && user.Account == account {
+			return user, true
+		}
+	}
+	return config.Users{}, false
+}
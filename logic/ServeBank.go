@@ -0,0 +1,52 @@
+package logic
+
+import (
+	"net"
+	"net/url"
+	"os"
+	"strconv"
+	"yatori-go-console/config"
+	"yatori-go-console/utils/qbank"
+
+	lg "github.com/yatori-dev/yatori-go-core/utils/log"
+)
+
+// ServeBank 本地题库服务，按外挂题库接口规范提供查询，autoExam为2时无需再部署第三方题库
+func ServeBank() {
+	addr := ":8083"
+	if fileExists("./config.yaml") {
+		configJson := config.ReadConfig("./config.yaml")
+		lg.LogInit(lg.StringToLOGLEVEL(configJson.Setting.BasicSetting.LogLevel), configJson.Setting.BasicSetting.LogOutFileSw == 1, configJson.Setting.BasicSetting.ColorLog, "./assets/log")
+		applyLocalBank(&configJson)
+		if listen := bankListenAddr(configJson.Setting.ApiQueSetting.Url); listen != "" {
+			addr = listen
+		}
+	}
+	//命令行指定的监听地址优先，比如 serve-bank :9000
+	if len(os.Args) > 2 {
+		addr = os.Args[2]
+	}
+	qbank.SetReadOnly(true) //刷课进程可能同时在写题库，服务只读并在文件变化后重新读取
+	lg.Print(lg.INFO, lg.Green, "Yatori --- ", "本地题库服务已启动，监听地址：", addr, "，题库共 ", strconv.Itoa(qbank.Count()), " 题")
+	err := qbank.Serve(addr)
+	if err != nil {
+		lg.Print(lg.INFO, lg.BoldRed, "本地题库服务启动失败：", err.Error())
+		os.Exit(0)
+	}
+}
+
+// bankListenAddr 从apiQueSetting.url中取出本地题库服务需要监听的端口
+func bankListenAddr(rawUrl string) string {
+	u, err := url.Parse(rawUrl)
+	if err != nil || u.Host == "" {
+		return ""
+	}
+	port := u.Port()
+	if port == "" {
+		port = "80"
+		if u.Scheme == "https" {
+			port = "443"
+		}
+	}
+	return net.JoinHostPort("", port)
+}
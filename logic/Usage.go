@@ -0,0 +1,65 @@
+package logic
+
+import (
+	"flag"
+	"sort"
+	"strconv"
+	"yatori-go-console/utils/aiusage"
+
+	lg "github.com/yatori-dev/yatori-go-core/utils/log"
+)
+
+// UsageReport AI用量查询命令，查看某一天各账号的AI请求次数、估算token数和费用
+//
+//	usage [-date 2006-01-02]
+func UsageReport(args []string) {
+	flags := flag.NewFlagSet("usage", flag.ExitOnError)
+	date := flags.String("date", "", "查看哪一天，不填为今天")
+	flags.Parse(args)
+
+	list := aiusage.Daily(*date)
+	if len(list) == 0 {
+		lg.Print(lg.INFO, lg.Purple, "Yatori --- ", "没有AI用量记录")
+		return
+	}
+	var total aiusage.Usage
+	for _, account := range sortedAccounts(list) {
+		u := list[account]
+		printUsageLine(account, u)
+		total.Requests += u.Requests
+		total.Failed += u.Failed
+		total.PromptTokens += u.PromptTokens
+		total.CompletionTokens += u.CompletionTokens
+		total.Cost += u.Cost
+	}
+	printUsageLine("合计", total)
+}
+
+// printUsage 打印本次运行的AI用量，没有请求过AI时不打印
+func printUsage() {
+	total, list := aiusage.Run()
+	if total.Requests == 0 {
+		return
+	}
+	lg.Print(lg.INFO, lg.Purple, "Yatori --- ", "本次运行AI用量（token数为估算值）：")
+	for _, account := range sortedAccounts(list) {
+		printUsageLine(account, list[account])
+	}
+	printUsageLine("合计", total)
+}
+
+// printUsageLine 打印一个账号的用量
+func printUsageLine(account string, u aiusage.Usage) {
+	lg.Print(lg.INFO, "[", lg.Green, account, lg.Default, "] ", "请求", strconv.Itoa(u.Requests), "次(失败", strconv.Itoa(u.Failed), "次) ",
+		"输入约", strconv.Itoa(u.PromptTokens), " token 输出约", strconv.Itoa(u.CompletionTokens), " token ", lg.Yellow, "估算费用", strconv.FormatFloat(u.Cost, 'f', 4, 64))
+}
+
+// sortedAccounts 按账号排序，输出顺序固定
+func sortedAccounts(list map[string]aiusage.Usage) []string {
+	accounts := make([]string, 0, len(list))
+	for account := range list {
+		accounts = append(accounts, account)
+	}
+	sort.Strings(accounts)
+	return accounts
+}
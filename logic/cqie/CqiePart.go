@@ -8,6 +8,9 @@ import (
 	"yatori-go-console/config"
 	utils2 "yatori-go-console/utils"
 	modelLog "yatori-go-console/utils/log"
+	"yatori-go-console/utils/pacing"
+	"yatori-go-console/utils/retry"
+	"yatori-go-console/utils/schedule"
 
 	"github.com/yatori-dev/yatori-go-core/aggregation/cqie"
 	cqieApi "github.com/yatori-dev/yatori-go-core/api/cqie"
@@ -62,7 +65,12 @@ var soundMut sync.Mutex
 
 func userBlock(setting config.Setting, user *config.Users, cache *cqieApi.CqieUserCache) {
 	// projectList, _ := enaea.ProjectListAction(cache) //拉取项目列表
-	courseList, _ := cqie.CqiePullCourseListAction(cache)
+	courseList, err := retry.DoValue(retry.Get(user.AccountType), "["+cache.Account+"] ", func() ([]cqie.CqieCourse, error) {
+		return cqie.CqiePullCourseListAction(cache)
+	})
+	if err != nil {
+		lg.Print(lg.INFO, "[", lg.Green, cache.Account, lg.Default, "] ", lg.BoldRed, "拉取课程列表失败：", err.Error())
+	}
 	for _, course := range courseList {
 		videosLock.Add(1)
 		go func() {
@@ -85,15 +93,21 @@ func userBlock(setting config.Setting, user *config.Users, cache *cqieApi.CqieUs
 func nodeListStudy(setting config.Setting, user *config.Users, userCache *cqieApi.CqieUserCache, course *cqie.CqieCourse) {
 	//过滤课程---------------------------------
 	//排除指定课程
-	if len(user.CoursesCustom.ExcludeCourses) != 0 && config.CmpCourse(course.CourseName, user.CoursesCustom.ExcludeCourses) {
+	if len(user.CoursesCustom.ExcludeCourses) != 0 && config.CmpCourse(course.CourseName, course.Id, user.CoursesCustom.ExcludeCourses) {
 		return
 	}
 	//包含指定课程
-	if len(user.CoursesCustom.IncludeCourses) != 0 && !config.CmpCourse(course.CourseName, user.CoursesCustom.IncludeCourses) {
+	if len(user.CoursesCustom.IncludeCourses) != 0 && !config.CmpCourse(course.CourseName, course.Id, user.CoursesCustom.IncludeCourses) {
 		return
 	}
 	//执行刷课---------------------------------
-	nodeList, _ := cqie.PullCourseVideoListAndProgress(userCache, course) //拉取对应课程的视频列表
+	nodeList, err := retry.DoValue(retry.Get(user.AccountType), "["+userCache.Account+"] ", func() ([]cqie.CqieVideo, error) {
+		return cqie.PullCourseVideoListAndProgress(userCache, course) //拉取对应课程的视频列表
+	})
+	if err != nil {
+		lg.Print(lg.INFO, "[", lg.Green, userCache.Account, lg.Default, "] ", "【"+course.CourseName+"】 ", lg.BoldRed, "拉取视频列表失败，该课程已跳过：", err.Error())
+		return
+	}
 	//失效重登检测
 	modelLog.ModelPrint(setting.BasicSetting.LogModel == 1, lg.INFO, "[", lg.Green, userCache.Account, lg.Default, "] ", "正在学习课程：", lg.Yellow, "【"+course.CourseName+"】 ")
 	// 提交学时
@@ -123,28 +137,38 @@ func videoAction(setting config.Setting, user *config.Users, UserCache *cqieApi.
 	startPos := node.StudyTime
 	stopPos := node.StudyTime
 	maxPos := node.StudyTime
+	pace := pacing.Get(user.AccountType)
+	bo := retry.NewBackoff(user.AccountType)
 	err := cqie.SaveVideoStudyTimeAction(UserCache, &node, startPos, stopPos) //每次刷课前都得先获取一遍，因为要获取学习分配的id
 	if err != nil {
 		lg.Print(lg.INFO, `[`, UserCache.Account, `] `, lg.BoldRed, err.Error())
 	}
 	for {
-		if maxPos >= node.TimeLength+3 { //+3是为了防止漏时
+		if maxPos >= node.TimeLength+pace.Step { //多加一个步长是为了防止漏时
 			startPos = node.TimeLength
 			stopPos = node.TimeLength
 			maxPos = node.TimeLength
 			break
 		}
 		if stopPos >= maxPos {
-			maxPos = startPos + 3
+			maxPos = startPos + pace.Step
 		}
+		schedule.WaitWindow(UserCache.Account) //不在学习时间窗口内则在此暂停
 		err := cqie.SubmitStudyTimeAction(UserCache, &node, nowTime, startPos, stopPos, maxPos)
 		if err != nil {
+			err = classifyError(err)
 			lg.Print(lg.INFO, "[", lg.Green, UserCache.Account, lg.Default, "] ", "【"+node.VideoName+"】", lg.BoldRed, "提交学时异常：", err.Error())
+			if !bo.Wait(err) {
+				lg.Print(lg.INFO, "[", lg.Green, UserCache.Account, lg.Default, "] ", "【"+node.VideoName+"】", lg.BoldRed, "多次提交学时失败，该视频已标记为失败并跳过")
+				return
+			}
+			continue
 		}
+		bo.Reset()
 		modelLog.ModelPrint(setting.BasicSetting.LogModel == 0, lg.INFO, "[", lg.Green, UserCache.Account, lg.Default, "] ", "【"+node.VideoName+"】  >>> ", "提交状态：", "成功", lg.Default, " ", "观看进度：", fmt.Sprintf("%.2f", float32(node.StudyTime)/float32(node.TimeLength)), "%")
-		startPos = startPos + 3
-		stopPos = stopPos + 3
-		time.Sleep(3 * time.Second)
+		startPos = startPos + pace.Step
+		stopPos = stopPos + pace.Step
+		pace.Wait()
 	}
 	err = cqie.SaveVideoStudyTimeAction(UserCache, &node, startPos, stopPos) //学完之后保存学习点
 	if err != nil {
@@ -158,6 +182,7 @@ func videoActionSecondBrush(setting config.Setting, user *config.Users, UserCach
 	if user.CoursesCustom.VideoModel == 0 { //是否打开了自动刷视频开关
 		return
 	}
+	schedule.WaitWindow(UserCache.Account) //不在学习时间窗口内则在此暂停
 	nowTime := time.Now()
 	startPos := node.StudyTime
 	stopPos := node.StudyTime
@@ -3,12 +3,15 @@ package enaea
 import (
 	"fmt"
 	"log"
-	"os"
 	"sync"
 	time2 "time"
 	"yatori-go-console/config"
 	utils2 "yatori-go-console/utils"
 	modelLog "yatori-go-console/utils/log"
+	"yatori-go-console/utils/pacing"
+	"yatori-go-console/utils/result"
+	"yatori-go-console/utils/retry"
+	"yatori-go-console/utils/schedule"
 
 	"github.com/yatori-dev/yatori-go-core/aggregation/enaea"
 	enaeaApi "github.com/yatori-dev/yatori-go-core/api/enaea"
@@ -63,21 +66,28 @@ var soundMut sync.Mutex
 
 func userBlock(setting config.Setting, user *config.Users, cache *enaeaApi.EnaeaUserCache) {
 
-	projectList, _ := enaea.ProjectListAction(cache) //拉取项目列表
+	projectList, err := retry.DoValue(retry.Get(user.AccountType), "["+cache.Account+"] ", func() ([]enaea.EnaeaProject, error) {
+		return enaea.ProjectListAction(cache) //拉取项目列表
+	})
+	if err != nil {
+		lg.Print(lg.INFO, "[", lg.Green, cache.Account, lg.Default, "] ", lg.BoldRed, "拉取项目列表失败：", err.Error())
+	}
 	for _, course := range projectList {
 		//过滤项目---------------------------------
 		//排除指定项目
-		if len(user.CoursesCustom.ExcludeCourses) != 0 && config.CmpCourse(course.ClusterName, user.CoursesCustom.ExcludeCourses) {
+		if len(user.CoursesCustom.ExcludeCourses) != 0 && config.CmpCourse(course.ClusterName, course.CircleId, user.CoursesCustom.ExcludeCourses) {
 			continue
 		}
 		//包含指定课程
-		if len(user.CoursesCustom.IncludeCourses) != 0 && !config.CmpCourse(course.ClusterName, user.CoursesCustom.IncludeCourses) {
+		if len(user.CoursesCustom.IncludeCourses) != 0 && !config.CmpCourse(course.ClusterName, course.CircleId, user.CoursesCustom.IncludeCourses) {
 			continue
 		}
-		courseList, err := enaea.CourseListAction(cache, course.CircleId)
+		courseList, err := retry.DoValue(retry.Get(user.AccountType), "["+cache.Account+"] ", func() ([]enaea.EnaeaCourse, error) {
+			return enaea.CourseListAction(cache, course.CircleId)
+		})
 		if err != nil {
-			lg.Print(lg.INFO, "[", lg.Green, cache.Account, lg.Default, "] ", lg.BoldRed, "拉取项目列表错误", err.Error())
-			os.Exit(0)
+			lg.Print(lg.INFO, "[", lg.Green, cache.Account, lg.Default, "] ", " 【"+course.ClusterName+"】 ", lg.BoldRed, "拉取课程列表失败，该项目已跳过：", err.Error())
+			continue
 		}
 		lg.Print(lg.INFO, "[", lg.Green, cache.Account, lg.Default, "] ", lg.Purple, "正在学习项目", " 【"+course.ClusterName+"】 ")
 		for _, item := range courseList { //遍历所有待刷视频
@@ -97,13 +107,15 @@ func userBlock(setting config.Setting, user *config.Users, cache *enaeaApi.Enaea
 // 章节节点的抽离函数
 func nodeListStudy(setting config.Setting, user *config.Users, userCache *enaeaApi.EnaeaUserCache, course *enaea.EnaeaCourse) {
 	//执行刷课---------------------------------
-	nodeList, err := enaea.VideoListAction(userCache, course) //拉取对应课程的视频列表
-	//失效重登检测
-	for err != nil {
+	nodeList, err := retry.DoValue(retry.Get(user.AccountType), "["+userCache.Account+"] ", func() ([]enaea.EnaeaVideo, error) {
+		nodeList, err := enaea.VideoListAction(userCache, course) //拉取对应课程的视频列表
+		//失效重登检测
 		enaea.LoginTimeoutAfreshAction(userCache, err)
-		nodeList1, err1 := enaea.VideoListAction(userCache, course) //拉取对应课程的视频列表
-		nodeList = nodeList1
-		err = err1
+		return nodeList, err
+	})
+	if err != nil {
+		lg.Print(lg.INFO, "[", lg.Green, userCache.Account, lg.Default, "] ", "【"+course.TitleTag+"】", "【"+course.CourseTitle+"】 ", lg.BoldRed, "拉取视频列表失败，该课程已跳过：", err.Error())
+		return
 	}
 	modelLog.ModelPrint(setting.BasicSetting.LogModel == 1, lg.INFO, "[", lg.Green, userCache.Account, lg.Default, "] ", "正在学习课程：", lg.Yellow, "【"+course.TitleTag+"】", "【"+course.CourseTitle+"】 ")
 	// 提交学时
@@ -126,11 +138,14 @@ func videoAction(setting config.Setting, user *config.Users, UserCache *enaeaApi
 	if err != nil {
 		lg.Print(lg.INFO, `[`, UserCache.Account, `] `, lg.BoldRed, "提交学时接口访问异常，返回信息：", err.Error())
 	}
+	pace := pacing.Get(user.AccountType)
+	bo := retry.NewBackoff(user.AccountType)
 	for {
 		if node.StudyProgress >= 100 {
 			modelLog.ModelPrint(setting.BasicSetting.LogModel == 0, lg.INFO, "[", lg.Green, UserCache.Account, lg.Default, "] ", " 【"+node.TitleTag+"】", " 【"+node.CourseName+"】", "【"+node.CourseContentStr+"】", " ", lg.Blue, "学习完毕")
 			break //如果看完了，也就是进度为100那么直接跳过
 		}
+		schedule.WaitWindow(UserCache.Account) //不在学习时间窗口内则在此暂停
 		//提交学时
 		var err error
 		if user.CoursesCustom.VideoModel == 1 {
@@ -139,16 +154,23 @@ func videoAction(setting config.Setting, user *config.Users, UserCache *enaeaApi
 			err = enaea.SubmitStudyTimeAction(UserCache, &node, 60, 1) //暴力模式
 		}
 
+		//失效重登检测
+		enaea.LoginTimeoutAfreshAction(UserCache, err)
 		if err != nil {
-			if err.Error() != "request frequently!" {
+			err = classifyError(err)
+			if !result.Is(err, result.RateLimited) { //请求过快只需要退避，不用打印
 				lg.Print(lg.INFO, `[`, UserCache.Account, `] `, " 【"+node.TitleTag+"】", "【"+node.CourseName+"】", "【"+node.CourseContentStr+"】 ", lg.BoldRed, "提交学时接口访问异常，返回信息：", err.Error())
 			}
+			if !bo.Wait(err) {
+				lg.Print(lg.INFO, "[", lg.Green, UserCache.Account, lg.Default, "] ", " 【"+node.TitleTag+"】", "【"+node.CourseName+"】", "【"+node.CourseContentStr+"】 ", lg.BoldRed, "多次提交学时失败，该视频已标记为失败并跳过")
+				break
+			}
+			continue
 		}
-		//失效重登检测
-		enaea.LoginTimeoutAfreshAction(UserCache, err)
+		bo.Reset()
 
 		modelLog.ModelPrint(setting.BasicSetting.LogModel == 0, lg.INFO, "[", lg.Green, UserCache.Account, lg.Default, "] ", " 【"+node.TitleTag+"】", "【"+node.CourseName+"】", "【"+node.CourseContentStr+"】  >>> ", "提交状态：", "成功", lg.Default, " ", "观看进度：", fmt.Sprintf("%.2f", node.StudyProgress), "%")
-		time2.Sleep(25 * time2.Second) //每隔25s进行一次学时提交
+		pace.Wait() //每隔一段时间进行一次学时提交
 		if node.StudyProgress >= 100 {
 			break //如果看完该视频则直接下一个
 		}
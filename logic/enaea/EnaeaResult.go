@@ -0,0 +1,21 @@
+package enaea
+
+import (
+	"yatori-go-console/utils/result"
+	"yatori-go-console/utils/retry"
+)
+
+// 学习公社错误信息的分类规则
+var resultRules = []result.Rule{
+	{Kind: result.SessionExpired, Keywords: []string{"nologin"}},
+	{Kind: result.RateLimited, Keywords: []string{"request frequently"}},
+}
+
+func init() {
+	retry.RegisterClassifier("ENAEA", result.Classifier(resultRules))
+}
+
+// classifyError 将学习公社接口返回的错误转为分类错误
+func classifyError(err error) error {
+	return result.Classify(err, resultRules)
+}
@@ -7,6 +7,8 @@ import (
 	"yatori-go-console/config"
 	utils2 "yatori-go-console/utils"
 	modelLog "yatori-go-console/utils/log"
+	"yatori-go-console/utils/retry"
+	"yatori-go-console/utils/schedule"
 
 	"github.com/thedevsaddam/gojsonq"
 	"github.com/yatori-dev/yatori-go-core/aggregation/ketangx"
@@ -85,11 +87,11 @@ func userBlock(setting config.Setting, user *config.Users, cache *ketangxApi.Ket
 func nodeListStudy(setting config.Setting, user *config.Users, userCache *ketangxApi.KetangxUserCache, course *ketangx.KetangxCourse) {
 	//过滤课程---------------------------------
 	//排除指定课程
-	if len(user.CoursesCustom.ExcludeCourses) != 0 && config.CmpCourse(course.Title, user.CoursesCustom.ExcludeCourses) {
+	if len(user.CoursesCustom.ExcludeCourses) != 0 && config.CmpCourse(course.Title, course.ActivityId, user.CoursesCustom.ExcludeCourses) {
 		return
 	}
 	//包含指定课程
-	if len(user.CoursesCustom.IncludeCourses) != 0 && !config.CmpCourse(course.Title, user.CoursesCustom.IncludeCourses) {
+	if len(user.CoursesCustom.IncludeCourses) != 0 && !config.CmpCourse(course.Title, course.ActivityId, user.CoursesCustom.IncludeCourses) {
 		return
 	}
 	//执行刷课---------------------------------
@@ -117,9 +119,12 @@ func videoAction(setting config.Setting, user *config.Users, UserCache *ketangxA
 	if node.IsComplete {
 		return
 	}
-	action, err := ketangx.CompleteVideoAction(UserCache, &node)
+	schedule.WaitWindow(UserCache.Account) //不在学习时间窗口内则在此暂停
+	action, err := retry.DoValue(retry.Get(user.AccountType), "["+UserCache.Account+"] ", func() (string, error) {
+		return ketangx.CompleteVideoAction(UserCache, &node)
+	})
 	if err != nil {
-		lg.Print(lg.INFO, "[", lg.Green, UserCache.Account, lg.Default, "] ", lg.Default, "【"+course.Title+"】 ", "【"+node.Title+"】", lg.BoldRed, "结点类型: ", "<", node.Type, "> ", "学习异常：", err.Error())
+		lg.Print(lg.INFO, "[", lg.Green, UserCache.Account, lg.Default, "] ", lg.Default, "【"+course.Title+"】 ", "【"+node.Title+"】", lg.BoldRed, "结点类型: ", "<", node.Type, "> ", "学习异常：", classifyError(err).Error())
 		return
 	}
 	status := gojsonq.New().JSONString(action).Find("Success")
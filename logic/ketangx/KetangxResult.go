@@ -0,0 +1,22 @@
+package ketangx
+
+import (
+	"yatori-go-console/utils/result"
+	"yatori-go-console/utils/retry"
+)
+
+// 码上研训错误信息的分类规则
+var resultRules = []result.Rule{
+	{Kind: result.SessionExpired, Keywords: []string{"登录"}},
+	{Kind: result.RateLimited, Keywords: []string{"频繁", "status code: 429"}},
+	{Kind: result.NotStarted, Keywords: []string{"未开始"}},
+}
+
+func init() {
+	retry.RegisterClassifier("KETANGX", result.Classifier(resultRules))
+}
+
+// classifyError 将码上研训接口返回的错误转为分类错误
+func classifyError(err error) error {
+	return result.Classify(err, resultRules)
+}
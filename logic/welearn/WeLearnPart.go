@@ -4,10 +4,12 @@ import (
 	"fmt"
 	"log"
 	"sync"
-	"time"
 	"yatori-go-console/config"
 	utils2 "yatori-go-console/utils"
 	modelLog "yatori-go-console/utils/log"
+	"yatori-go-console/utils/pacing"
+	"yatori-go-console/utils/retry"
+	"yatori-go-console/utils/schedule"
 
 	action "github.com/yatori-dev/yatori-go-core/aggregation/welearn"
 	"github.com/yatori-dev/yatori-go-core/api/welearn"
@@ -62,9 +64,11 @@ var soundMut sync.Mutex
 
 func userBlock(setting config.Setting, user *config.Users, cache *welearn.WeLearnUserCache) {
 	// projectList, _ := enaea.ProjectListAction(cache) //拉取项目列表
-	courseList, err := action.WeLearnPullCourseListAction(cache)
+	courseList, err := retry.DoValue(retry.Get(user.AccountType), "["+cache.Account+"] ", func() ([]action.WeLearnCourse, error) {
+		return action.WeLearnPullCourseListAction(cache)
+	})
 	if err != nil {
-		panic(err)
+		lg.Print(lg.INFO, "[", lg.Green, cache.Account, lg.Default, "] ", lg.BoldRed, "拉取课程列表失败：", err.Error())
 	}
 	for _, course := range courseList {
 		videosLock.Add(1)
@@ -88,11 +92,11 @@ func userBlock(setting config.Setting, user *config.Users, cache *welearn.WeLear
 func nodeListStudy(setting config.Setting, user *config.Users, userCache *welearn.WeLearnUserCache, course *action.WeLearnCourse) {
 	//过滤课程---------------------------------
 	//排除指定课程
-	if len(user.CoursesCustom.ExcludeCourses) != 0 && config.CmpCourse(course.Name, user.CoursesCustom.ExcludeCourses) {
+	if len(user.CoursesCustom.ExcludeCourses) != 0 && config.CmpCourse(course.Name, course.Cid, user.CoursesCustom.ExcludeCourses) {
 		return
 	}
 	//包含指定课程
-	if len(user.CoursesCustom.IncludeCourses) != 0 && !config.CmpCourse(course.Name, user.CoursesCustom.IncludeCourses) {
+	if len(user.CoursesCustom.IncludeCourses) != 0 && !config.CmpCourse(course.Name, course.Cid, user.CoursesCustom.IncludeCourses) {
 		return
 	}
 	//执行刷课---------------------------------
@@ -101,14 +105,21 @@ func nodeListStudy(setting config.Setting, user *config.Users, userCache *welear
 	//失效重登检测
 	modelLog.ModelPrint(setting.BasicSetting.LogModel == 1, lg.INFO, "[", lg.Green, userCache.Account, lg.Default, "] ", "正在学习课程：", lg.Yellow, "【"+course.Name+"】 ")
 	// 提交学时
-	chapterList, err := action.WeLearnPullCourseChapterAction(userCache, *course) //拉取对应课程的章节
+	policy := retry.Get(user.AccountType)
+	chapterList, err := retry.DoValue(policy, "["+userCache.Account+"] ", func() ([]action.WeLearnChapter, error) {
+		return action.WeLearnPullCourseChapterAction(userCache, *course) //拉取对应课程的章节
+	})
 	if err != nil {
-		panic(err)
+		lg.Print(lg.INFO, "[", lg.Green, userCache.Account, lg.Default, "] ", "【"+course.Name+"】 ", lg.BoldRed, "拉取章节失败，该课程已跳过：", err.Error())
+		return
 	}
 	for _, chapter := range chapterList {
-		pointList, err1 := action.WeLearnPullChapterPointAction(userCache, *course, chapter)
+		pointList, err1 := retry.DoValue(policy, "["+userCache.Account+"] ", func() ([]action.WeLearnPoint, error) {
+			return action.WeLearnPullChapterPointAction(userCache, *course, chapter)
+		})
 		if err1 != nil {
-			panic(err1)
+			lg.Print(lg.INFO, "[", lg.Green, userCache.Account, lg.Default, "] ", "【"+course.Name+"】 ", lg.BoldRed, "拉取任务点失败，该章节已跳过：", err1.Error())
+			continue
 		}
 		for _, point := range pointList {
 			//视频处理逻辑
@@ -138,8 +149,11 @@ func nodeCompleteAction(setting config.Setting, user *config.Users, UserCache *w
 	if node.IsComplete == "completed" {
 		return
 	}
+	schedule.WaitWindow(UserCache.Account) //不在学习时间窗口内则在此暂停
 	//action, err := ketangx.CompleteVideoAction(UserCache, &node)
-	err := action.WeLearnCompletePointAction(UserCache, *course, node)
+	err := retry.Do(retry.Get(user.AccountType), "["+UserCache.Account+"] ", func() error {
+		return action.WeLearnCompletePointAction(UserCache, *course, node)
+	})
 	if err != nil {
 		lg.Print(lg.INFO, "[", lg.Green, UserCache.Account, lg.Default, "] ", lg.Default, "【"+course.Name+"】 ", "【"+node.Name+"】", lg.BoldRed, "学习异常：", err.Error())
 		return
@@ -163,33 +177,51 @@ func nodeSubmitTimeAction(setting config.Setting, user *config.Users, UserCache
 	//	return
 	//}
 
-	_, progressMeasure, sessionTime, totalTime, scaled, err := action.WeLearnSubmitStudyTimeAction(UserCache, *course, node)
+	policy := retry.Get(user.AccountType)
+	var progressMeasure, sessionTime, totalTime int
+	var scaled string
+	err := retry.Do(policy, "["+UserCache.Account+"] ", func() error {
+		var err error
+		_, progressMeasure, sessionTime, totalTime, scaled, err = action.WeLearnSubmitStudyTimeAction(UserCache, *course, node)
+		return err
+	})
 	if err != nil {
-		fmt.Println(err)
+		lg.Print(lg.INFO, "[", lg.Green, UserCache.Account, lg.Default, "] ", lg.Default, "【"+course.Name+"】 ", "【"+node.Location+"】", lg.BoldRed, "开始学习失败，该任务点已标记为失败并跳过：", err.Error())
+		return
 	}
 	endTime := 1600
 	//比阈值大就直接返回
 	if totalTime > endTime {
 		return
 	}
+	pace := pacing.Get(user.AccountType)
+	bo := &retry.Backoff{Policy: policy}
 	for {
+		schedule.WaitWindow(UserCache.Account) //不在学习时间窗口内则在此暂停
 		api, err1 := UserCache.KeepPointSessionPlan1Api(course.Cid, node.Id, course.Uid, course.ClassId, sessionTime, totalTime, 3, nil)
 		if err1 != nil {
-			fmt.Println(err1)
+			err1 = classifyError(err1)
+			lg.Print(lg.INFO, "[", lg.Green, UserCache.Account, lg.Default, "] ", lg.Default, "【"+course.Name+"】 ", "【"+node.Location+"】", lg.BoldRed, "学时提交异常：", err1.Error())
+			if !bo.Wait(err1) {
+				lg.Print(lg.INFO, "[", lg.Green, UserCache.Account, lg.Default, "] ", lg.Default, "【"+course.Name+"】 ", "【"+node.Location+"】", lg.BoldRed, "多次提交学时失败，该任务点已标记为失败并跳过")
+				return
+			}
+			continue
 		}
+		bo.Reset()
 		//fmt.Println(totalTime, api)
 		lg.Print(lg.INFO, "[", lg.Green, UserCache.Account, lg.Default, "] ", lg.Default, "【"+course.Name+"】 ", "【"+node.Location+"】", lg.Green, "学时提交成功，进度: ", fmt.Sprintf("%d/%d", totalTime, endTime), "服务器返回信息: ", api)
 		if sessionTime >= endTime {
 			break
 		}
-		sessionTime += 60
-		totalTime += 60
-		time.Sleep(time.Duration(60) * time.Second)
-	}
-	_, err2 := UserCache.SubmitStudyPlan2Api(course.Cid, node.Id, course.Uid, scaled, course.ClassId, progressMeasure, "completed", 3, nil)
-	if err2 != nil {
-		fmt.Println(err2)
-	}
+		sessionTime += pace.Step
+		totalTime += pace.Step
+		pace.Wait()
+	}
+	err = retry.Do(policy, "["+UserCache.Account+"] ", func() error {
+		_, err2 := UserCache.SubmitStudyPlan2Api(course.Cid, node.Id, course.Uid, scaled, course.ClassId, progressMeasure, "completed", 3, nil)
+		return err2
+	})
 	//fmt.Println(submitApi2)
 
 	if err != nil {
@@ -0,0 +1,62 @@
+package xuexitong
+
+import (
+	"strings"
+	"yatori-go-console/config"
+	"yatori-go-console/utils/audit"
+
+	"github.com/yatori-dev/yatori-go-core/api/entity"
+	lg "github.com/yatori-dev/yatori-go-core/utils/log"
+	"github.com/yatori-dev/yatori-go-core/utils/qutils"
+)
+
+// fixedPatternScores 计算AnswerFixedPattern修正前每道选择、判断题答案与最接近选项的相似度，取各答案中的最低值，key为题目ID
+func fixedPatternScores(questionAction *entity.Question) map[string]float64 {
+	scores := map[string]float64{}
+	score := func(answers, candidates []string) float64 {
+		min := 1.0
+		for _, answer := range answers {
+			if best := qutils.SimilarityArrayAndSort(answer, candidates)[0].Score; best < min {
+				min = best
+			}
+		}
+		return min
+	}
+	for _, choice := range questionAction.Choice {
+		if len(choice.Answers) > 0 && len(choice.Options) > 0 {
+			scores[choice.Qid] = score(choice.Answers, optionTexts(choice.Options))
+		}
+	}
+	for _, judge := range questionAction.Judge {
+		var answers []string
+		for _, answer := range judge.Answers {
+			answers = append(answers, strings.ReplaceAll(answer, "对", "正确"))
+		}
+		if len(answers) > 0 {
+			scores[judge.Qid] = score(answers, []string{"正确", "错误"})
+		}
+	}
+	return scores
+}
+
+// auditQuestion 将提交的章节作业逐题写入答题记录，sources、scores和correct的key为题目ID
+func auditQuestion(user *config.Users, courseName, title string, questionAction *entity.Question, sources map[string]string, scores map[string]float64, correct map[string]bool) {
+	var records []audit.Record
+	for _, q := range allQuestions(questionAction) {
+		info := questionInfo(q)
+		record := audit.Record{Account: user.Account, Platform: user.AccountType, Course: courseName, Title: title, Kind: "work",
+			Type: info.qType, Content: info.text, Options: optionTexts(info.options), Source: sources[info.qid], Similarity: scores[info.qid]}
+		if info.answers != nil {
+			record.Answers = *info.answers
+		} else {
+			record.Answers = blankAnswers(info.blanks)
+		}
+		if ok, graded := correct[info.qid]; graded {
+			record.Correct = &ok
+		}
+		records = append(records, record)
+	}
+	if err := audit.Append(records); err != nil {
+		lg.Print(lg.INFO, "[", lg.Green, user.Account, lg.Default, "] ", "【", title, "】 ", lg.BoldRed, "写入答题记录失败：", err.Error())
+	}
+}
@@ -0,0 +1,94 @@
+package xuexitong
+
+import (
+	"errors"
+	"math/rand"
+	"strconv"
+	"strings"
+	"yatori-go-console/config"
+	"yatori-go-console/utils/aiusage"
+	"yatori-go-console/utils/prompt"
+
+	"github.com/thedevsaddam/gojsonq"
+	"github.com/yatori-dev/yatori-go-core/aggregation/xuexitong"
+	"github.com/yatori-dev/yatori-go-core/aggregation/xuexitong/point"
+	"github.com/yatori-dev/yatori-go-core/api/entity"
+	xuexitongApi "github.com/yatori-dev/yatori-go-core/api/xuexitong"
+	"github.com/yatori-dev/yatori-go-core/que-core/aiq"
+	"github.com/yatori-dev/yatori-go-core/que-core/qtype"
+)
+
+// bbsReply 按账号的讨论回复策略生成回复内容，模板方式渲染固定模板，其余方式由AI回复
+func bbsReply(cache *xuexitongApi.XueXiTUserCache, user *config.Users, aiSetting config.AiSetting, courseName string, bbsTopic *point.BBsTopic) (string, error) {
+	strategy := user.CoursesCustom.BBSReply
+	data := prompt.Data{Platform: user.AccountType, Account: user.Account, Course: courseName, Title: bbsTopic.Title, Type: "讨论", Content: bbsTopic.Content}
+	if !user.CoursesCustom.BBSUseAI() {
+		if len(strategy.Templates) == 0 {
+			return "", errors.New("未配置讨论回复模板")
+		}
+		//多个模板随机选一个，避免每个讨论的回复完全一样
+		reply, err := prompt.Render(strategy.Templates[rand.Intn(len(strategy.Templates))], data)
+		if err != nil {
+			return "", errors.New("讨论回复模板有误：" + err.Error())
+		}
+		return clipReply(strings.TrimSpace(reply), strategy.MaxLength), nil
+	}
+	que := entity.EssayQue{Type: qtype.Essay, Text: bbsTopic.Content, OpFromAnswer: map[string][]string{}}
+	message := xuexitong.AIProblemMessage(bbsTopic.Title, que.Type.String(), entity.ExamTurn{XueXEssayQue: que})
+	message = customPrompt(cache, user, aiSetting, prompt.KindBBS, data, message)
+	message = replyRequirement(message, strategy)
+	raw, err := aiusage.Chat(user.Account, aiSetting.AiType, aiSetting.AiUrl, aiSetting.Model, aiSetting.APIKEY, message)
+	if err != nil {
+		return "", err
+	}
+	answers, ok := parseAIAnswer(raw)
+	if !ok {
+		return "", errors.New("AI回复无法解析：" + raw)
+	}
+	return clipReply(strings.TrimSpace(answers[0]), strategy.MaxLength), nil
+}
+
+// replyRequirement 在题目描述后追加语气和字数要求
+func replyRequirement(message aiq.AIChatMessages, strategy config.BBSReply) aiq.AIChatMessages {
+	var requirement []string
+	if strategy.Tone != "" {
+		requirement = append(requirement, "回复语气："+strategy.Tone)
+	}
+	if strategy.MaxLength > 0 {
+		requirement = append(requirement, "回复不超过"+strconv.Itoa(strategy.MaxLength)+"字")
+	}
+	if len(requirement) == 0 || len(message.Messages) == 0 {
+		return message
+	}
+	res := append([]aiq.Message(nil), message.Messages...)
+	last := len(res) - 1
+	res[last].Content += "\n" + strings.Join(requirement, "，")
+	return aiq.AIChatMessages{Messages: res}
+}
+
+// clipReply 回复超过最多字数时截断，尽量停在句末
+func clipReply(reply string, maxLength int) string {
+	runes := []rune(reply)
+	if maxLength <= 0 || len(runes) <= maxLength {
+		return reply
+	}
+	runes = runes[:maxLength]
+	for i := len(runes) - 1; i >= maxLength/2; i-- {
+		if strings.ContainsRune("。！？!?；;", runes[i]) {
+			return string(runes[:i+1])
+		}
+	}
+	return string(runes)
+}
+
+// postBBS 发布讨论回复，返回服务器返回信息
+func postBBS(cache *xuexitongApi.XueXiTUserCache, bbsTopic *point.BBsTopic, bbsDto *entity.PointBBsDto, reply string) (string, error) {
+	res, err := cache.AnswerBbsApi(bbsTopic.Uuid, bbsDto.CourseID, bbsDto.ClassID, reply, bbsTopic.UrlToken, bbsTopic.Bbsid, 3, nil)
+	if err != nil {
+		return "", err
+	}
+	if status, ok := gojsonq.New().JSONString(res).Find("status").(bool); !ok || !status {
+		return "", errors.New(res)
+	}
+	return res, nil
+}
@@ -0,0 +1,94 @@
+package xuexitong
+
+import (
+	"regexp"
+	"sort"
+	"strconv"
+	"yatori-go-console/utils/qbank"
+)
+
+// optionTexts 取出选项内容
+func optionTexts(options map[string]string) []string {
+	var texts []string
+	for _, option := range options {
+		texts = append(texts, option)
+	}
+	return texts
+}
+
+var blankIndexReg = regexp.MustCompile(`第(\d+)空`)
+
+// blankIndex 取出key中“第X空”的X，与core的extractIndexFromKey一致，没有时返回-1
+func blankIndex(key string) int {
+	if matches := blankIndexReg.FindStringSubmatch(key); len(matches) >= 2 {
+		if index, err := strconv.Atoi(matches[1]); err == nil {
+			return index
+		}
+	}
+	return -1
+}
+
+// blankKeys 按顺序取出填空(简答)题每个空的key，按“第X空”的数字排序，避免第10空排在第2空前面
+func blankKeys(blanks map[string][]string) []string {
+	var keys []string
+	for key := range blanks {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		a, b := blankIndex(keys[i]), blankIndex(keys[j])
+		if a != b {
+			return a < b
+		}
+		return keys[i] < keys[j]
+	})
+	return keys
+}
+
+// bankOptions 从本地题库查询选择、判断题答案，命中则直接赋值并返回题库中答案的来源
+func bankOptions(qType, text string, options map[string]string, answers *[]string) (string, bool) {
+	texts := optionTexts(options)
+	entry, ok := qbank.Lookup(qType, text, texts)
+	if !ok {
+		return "", false
+	}
+	*answers = qbank.MatchOptions(entry.Answers, texts)
+	return entry.Source, true
+}
+
+// bankBlanks 从本地题库查询填空、简答类题目答案，命中则按空的顺序赋值并返回题库中答案的来源
+func bankBlanks(qType, text string, blanks map[string][]string) (string, bool) {
+	entry, ok := qbank.Lookup(qType, text, nil)
+	if !ok {
+		return "", false
+	}
+	for i, key := range blankKeys(blanks) {
+		if i < len(entry.Answers) {
+			blanks[key] = []string{entry.Answers[i]}
+		}
+	}
+	return entry.Source, true
+}
+
+// blankAnswers 按空的顺序取出填空、简答类题目的答案，没有作答的空为空字符串
+func blankAnswers(blanks map[string][]string) []string {
+	var answers []string
+	for _, key := range blankKeys(blanks) {
+		answer := ""
+		if len(blanks[key]) > 0 {
+			answer = blanks[key][0]
+		}
+		answers = append(answers, answer)
+	}
+	return answers
+}
+
+// storeBlanks 将填空、简答类题目答案按空的顺序写入本地题库
+func storeBlanks(qType, text string, blanks map[string][]string, source string) {
+	answers := blankAnswers(blanks)
+	for _, answer := range answers {
+		if answer != "" { //至少有一个空有答案才写入
+			qbank.Store(qbank.Entry{Type: qType, Content: text, Answers: answers, Source: source})
+			return
+		}
+	}
+}
@@ -0,0 +1,26 @@
+package xuexitong
+
+import (
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+func TestBlankKeys(t *testing.T) {
+	blanks := map[string][]string{}
+	var want []string
+	for i := 1; i <= 12; i++ {
+		key := "0第" + strconv.Itoa(i) + "空"
+		blanks[key] = []string{"答案" + strconv.Itoa(i)}
+		want = append(want, key)
+	}
+	if got := blankKeys(blanks); !reflect.DeepEqual(got, want) {
+		t.Fatalf("blankKeys = %v, want %v", got, want)
+	}
+	answers := blankAnswers(blanks)
+	for i, answer := range answers {
+		if answer != "答案"+strconv.Itoa(i+1) {
+			t.Fatalf("blankAnswers[%d] = %q, 空的顺序错误：%v", i, answer, answers)
+		}
+	}
+}
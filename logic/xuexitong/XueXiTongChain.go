@@ -0,0 +1,189 @@
+package xuexitong
+
+import (
+	"encoding/json"
+	"errors"
+	"strconv"
+	"yatori-go-console/config"
+	"yatori-go-console/utils/aiusage"
+	"yatori-go-console/utils/health"
+	"yatori-go-console/utils/qbank"
+
+	"github.com/yatori-dev/yatori-go-core/api/entity"
+	xuexitongApi "github.com/yatori-dev/yatori-go-core/api/xuexitong"
+	"github.com/yatori-dev/yatori-go-core/que-core/aiq"
+	"github.com/yatori-dev/yatori-go-core/que-core/external"
+	"github.com/yatori-dev/yatori-go-core/que-core/qentity"
+	lg "github.com/yatori-dev/yatori-go-core/utils/log"
+)
+
+// questionFields 各题型共有的字段，选择判断题有选项和答案，其余题型只有各个空
+type questionFields struct {
+	qid, qType, text string
+	options          map[string]string
+	answers          *[]string
+	blanks           map[string][]string
+}
+
+// questionInfo 取出题目的共有字段
+func questionInfo(q entity.AnswerSetter) questionFields {
+	switch v := q.(type) {
+	case *entity.ChoiceQue:
+		return questionFields{qid: v.Qid, qType: v.Type.String(), text: v.Text, options: v.Options, answers: &v.Answers}
+	case *entity.JudgeQue:
+		return questionFields{qid: v.Qid, qType: v.Type.String(), text: v.Text, options: v.Options, answers: &v.Answers}
+	case *entity.FillQue:
+		return questionFields{qid: v.Qid, qType: v.Type.String(), text: v.Text, blanks: v.OpFromAnswer}
+	case *entity.ShortQue:
+		return questionFields{qid: v.Qid, qType: v.Type.String(), text: v.Text, blanks: v.OpFromAnswer}
+	case *entity.TermExplanationQue:
+		return questionFields{qid: v.Qid, qType: v.Type.String(), text: v.Text, blanks: v.OpFromAnswer}
+	case *entity.EssayQue:
+		return questionFields{qid: v.Qid, qType: v.Type.String(), text: v.Text, blanks: v.OpFromAnswer}
+	}
+	return questionFields{}
+}
+
+// allQuestions 按题型顺序取出作业中的所有题目
+func allQuestions(questionAction *entity.Question) []entity.AnswerSetter {
+	var list []entity.AnswerSetter
+	for i := range questionAction.Choice {
+		list = append(list, &questionAction.Choice[i])
+	}
+	for i := range questionAction.Judge {
+		list = append(list, &questionAction.Judge[i])
+	}
+	for i := range questionAction.Fill {
+		list = append(list, &questionAction.Fill[i])
+	}
+	for i := range questionAction.Short {
+		list = append(list, &questionAction.Short[i])
+	}
+	for i := range questionAction.TermExplanation {
+		list = append(list, &questionAction.TermExplanation[i])
+	}
+	for i := range questionAction.Essay {
+		list = append(list, &questionAction.Essay[i])
+	}
+	return list
+}
+
+// parseAIAnswer 解析AI回复的答案数组
+func parseAIAnswer(raw string) ([]string, bool) {
+	var answers []string
+	if err := json.Unmarshal([]byte(raw), &answers); err != nil || len(answers) == 0 {
+		return nil, false
+	}
+	return answers, true
+}
+
+// chainAnswer 按账号配置的答案来源顺序为一道题作答，前一个来源没有答案或答案与选项对不上时使用下一个，
+// 返回答案来源说明（比如ai:TONGYI/qwen-plus、external、bank(ai)）以及是否需要留给人工审核，AI用量超出上限且配置为暂停答题时返回aiusage.ErrPaused
+func chainAnswer(userCache *xuexitongApi.XueXiTUserCache, user *config.Users, setting config.Setting, title string, q entity.AnswerSetter, message aiq.AIChatMessages) (string, bool, error) {
+	info := questionInfo(q)
+	qType, text := info.qType, info.text
+	texts := optionTexts(info.options)
+	var fallback []string //所有来源都没有答案时使用的答案，与原先AI回复无法解析时的处理一致
+	var lastErr error
+	chains := user.CoursesCustom.AnswerSources()
+	if qbank.Verified(qType, text, texts) { //批改确认过的答案优先于其他所有来源
+		chains = append([]string{config.AnswerBank}, chains...)
+	}
+	for _, chain := range chains {
+		if chain != config.AnswerBank && !health.Healthy(chain) { //运行中故障的来源暂时跳过
+			continue
+		}
+		var res []string
+		source := qbank.SourceAI //写入本地题库时的来源
+		label := chain           //答题记录中的来源说明
+		switch chain {
+		case config.AnswerBank:
+			var from string
+			var hit bool
+			if info.answers != nil {
+				from, hit = bankOptions(qType, text, info.options, info.answers)
+			} else {
+				from, hit = bankBlanks(qType, text, info.blanks)
+			}
+			if hit {
+				return "bank(" + from + ")", false, nil
+			}
+			continue
+		case config.AnswerExternal:
+			source = qbank.SourceExternal
+			request, err := external.ApiQueRequest(qentity.Question{Type: qType, Content: text, Options: texts}, setting.ApiQueSetting.Url, 3, nil)
+			health.Report(health.External, err)
+			if err != nil {
+				lastErr = err
+				continue
+			}
+			if request != nil {
+				res = request.Answers
+			}
+		case config.AnswerAI:
+			aiSetting := setting.AiSetting //获取AI设置
+			//共识模式，询问所有AI按多数答案作答
+			if aiSetting.ConsensusOn() {
+				err := consensusAnswer(userCache, user, aiSetting, title, qType, text, texts, message, q)
+				if errors.Is(err, errDisputed) {
+					return "ai:consensus", true, nil
+				}
+				if errors.Is(err, aiusage.ErrPaused) {
+					return "", false, err
+				}
+				if err != nil {
+					lastErr, fallback = err, []string{"A"}
+					continue
+				}
+				storeAnswer(q, source)
+				return "ai:consensus", false, nil
+			}
+			label = "ai:" + aiSetting.Name()
+			aiAnswer, err := aiusage.Chat(user.Account, aiSetting.AiType, aiSetting.AiUrl, aiSetting.Model, aiSetting.APIKEY, message)
+			if errors.Is(err, aiusage.ErrPaused) {
+				return "", false, err
+			}
+			if err != nil {
+				lastErr = err
+				continue
+			}
+			parsed, ok := parseAIAnswer(aiAnswer)
+			if !ok {
+				fallback = []string{"A"}
+				continue
+			}
+			res = parsed
+		}
+		if res = normalizeAnswers(q, res); len(res) == 0 {
+			continue
+		}
+		//判断题的答案常写作“正确”“对”等，与选项文字不一定相同，不做相似度检查
+		if _, judge := q.(*entity.JudgeQue); !judge {
+			if score := qbank.OptionScore(res, texts); score < user.CoursesCustom.MinSimilarity() {
+				lg.Print(lg.INFO, "[", lg.Green, userCache.Name, lg.Default, "] ", "【", title, "】", lg.Yellow, chain, "答案与选项相似度过低(", strconv.FormatFloat(score, 'f', 2, 64), ")，尝试下一个答案来源，题目：", text)
+				continue
+			}
+		}
+		q.SetAnswers(res)
+		storeAnswer(q, source)
+		return label, false, nil
+	}
+	if lastErr != nil {
+		lg.Print(lg.INFO, "[", lg.Green, userCache.Name, lg.Default, "] ", "【", title, "】", lg.BoldRed, "所有答案来源均未给出答案，最后一次异常：", lastErr.Error(), "，题目：", text)
+	}
+	if fallback != nil {
+		q.SetAnswers(fallback)
+		return "random", false, nil
+	}
+	return "", false, nil
+}
+
+// storeAnswer 将一道题的答案写入本地题库，供其他账号直接使用
+func storeAnswer(q entity.AnswerSetter, source string) {
+	info := questionInfo(q)
+	if info.answers != nil {
+		qbank.Store(qbank.Entry{Type: info.qType, Content: info.text, Options: optionTexts(info.options), Answers: *info.answers, Source: source})
+		return
+	}
+	storeBlanks(info.qType, info.text, info.blanks, source)
+}
@@ -0,0 +1,52 @@
+package xuexitong
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"yatori-go-console/config"
+	"yatori-go-console/utils/consensus"
+
+	"github.com/yatori-dev/yatori-go-core/api/entity"
+	xuexitongApi "github.com/yatori-dev/yatori-go-core/api/xuexitong"
+	"github.com/yatori-dev/yatori-go-core/que-core/aiq"
+	lg "github.com/yatori-dev/yatori-go-core/utils/log"
+)
+
+// errDisputed 共识模式下各AI答案不一致且配置为留给人工审核
+var errDisputed = errors.New("各AI答案不一致")
+
+// consensusAnswer 共识模式答题，同一道题询问所有AI并采用多数答案，答案不一致时记录分歧
+func consensusAnswer(userCache *xuexitongApi.XueXiTUserCache, user *config.Users, aiSetting config.AiSetting, title, qType, text string, options []string, message aiq.AIChatMessages, as entity.AnswerSetter) error {
+	var providers []consensus.Provider
+	for _, p := range aiSetting.AllProviders() {
+		providers = append(providers, consensus.Provider{AiType: p.AiType, AiUrl: p.AiUrl, Model: p.Model, APIKEY: p.APIKEY})
+	}
+	//先统一答案格式再投票，避免选项字母和选项内容被当成不同答案
+	votes, err := consensus.Ask(user.Account, providers, message, func(raw string) ([]string, bool) {
+		answers, ok := parseAIAnswer(raw)
+		if !ok {
+			return nil, false
+		}
+		return normalizeAnswers(as, answers), true
+	})
+	if err != nil { //用量超出上限暂停答题
+		return err
+	}
+	res, err := consensus.Decide(votes)
+	if err != nil {
+		return err
+	}
+	as.SetAnswers(res.Answers)
+	if res.Agreed {
+		return nil
+	}
+	disputed := aiSetting.DisagreeReview == 1
+	consensus.Record(consensus.Disagreement{Account: user.Account, Platform: user.AccountType, Title: title, Type: qType, Content: text, Options: options,
+		Votes: votes, Chosen: res.Answers, Review: disputed, Support: res.Support, Total: len(votes)})
+	lg.Print(lg.INFO, "[", lg.Green, userCache.Name, lg.Default, "] ", "【", title, "】", lg.Yellow, "各AI答案不一致，采用", strconv.Itoa(res.Support), "/", strconv.Itoa(len(votes)), "票的答案：", strings.Join(res.Answers, ","), "，题目：", text)
+	if disputed {
+		return errDisputed
+	}
+	return nil
+}
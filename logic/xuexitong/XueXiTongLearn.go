@@ -0,0 +1,86 @@
+package xuexitong
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"yatori-go-console/utils/graded"
+	"yatori-go-console/utils/qbank"
+
+	"github.com/thedevsaddam/gojsonq"
+	"github.com/yatori-dev/yatori-go-core/api/entity"
+	xuexitongApi "github.com/yatori-dev/yatori-go-core/api/xuexitong"
+	lg "github.com/yatori-dev/yatori-go-core/utils/log"
+)
+
+// gradedHost 交卷返回的批改页面地址是相对路径
+const gradedHost = "https://mooc1.chaoxing.com"
+
+// sortedOptions 按选项字母顺序排列选项内容
+func sortedOptions(options map[string]string) []string {
+	var keys []string
+	for key := range options {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	var texts []string
+	for _, key := range keys {
+		texts = append(texts, options[key])
+	}
+	return texts
+}
+
+// learnQuestion 交卷后根据返回的批改页面地址拉取答卷，把平台公布的正确答案以verified来源写入本地题库，
+// 返回每道题是否答对，key为题目ID，平台没有公布答案时返回空
+func learnQuestion(userCache *xuexitongApi.XueXiTUserCache, title string, questionAction *entity.Question, resultStr string) map[string]bool {
+	if gojsonq.New().JSONString(resultStr).Find("status") != true {
+		return nil
+	}
+	url, _ := gojsonq.New().JSONString(resultStr).Find("url").(string)
+	if url == "" {
+		return nil
+	}
+	if strings.HasPrefix(url, "/") {
+		url = gradedHost + url
+	}
+	html, err := graded.Fetch(url, userCache.GetCookies())
+	if err != nil {
+		return nil
+	}
+	items := graded.Parse(html)
+	if len(items) == 0 {
+		return nil
+	}
+	correct := map[string]bool{}
+	learned := 0
+	matcher := graded.NewMatcher(items)
+	for _, q := range allQuestions(questionAction) {
+		info := questionInfo(q)
+		var options []string
+		if _, choice := q.(*entity.ChoiceQue); choice { //选择题的选项也要对应上，判断题的选项在批改页面中不一定显示
+			options = optionTexts(info.options)
+		}
+		item, ok, unique := matcher.Find(info.text, options)
+		if !ok || !unique { //对应不唯一时不作为正确答案写入题库
+			continue
+		}
+		var answers []string
+		switch q.(type) {
+		case *entity.ChoiceQue, *entity.JudgeQue:
+			_, judge := q.(*entity.JudgeQue)
+			answers = graded.Resolve(item.Answers, sortedOptions(info.options), judge)
+			correct[info.qid] = graded.Same(*info.answers, answers)
+		case *entity.FillQue: //多个空按顺序对应
+			answers = item.Answers
+			correct[info.qid] = graded.Same(blankAnswers(info.blanks), answers)
+		default: //简答类题目答案不拆分，也无法判断是否答对
+			answers = []string{item.Raw}
+		}
+		qbank.Store(qbank.Entry{Type: info.qType, Content: info.text, Options: optionTexts(info.options), Answers: answers, Source: qbank.SourceVerified})
+		learned++
+	}
+	if learned > 0 {
+		lg.Print(lg.INFO, "[", lg.Green, userCache.Name, lg.Default, "] ", "【", title, "】", lg.Green, "已从批改结果中学习", strconv.Itoa(learned), "道题的正确答案")
+	}
+	return correct
+}
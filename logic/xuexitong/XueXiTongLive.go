@@ -0,0 +1,48 @@
+package xuexitong
+
+import (
+	"sync"
+	"time"
+	"yatori-go-console/config"
+	"yatori-go-console/utils/schedule"
+
+	"github.com/yatori-dev/yatori-go-core/aggregation/xuexitong"
+	"github.com/yatori-dev/yatori-go-core/aggregation/xuexitong/point"
+	"github.com/yatori-dev/yatori-go-core/api/entity"
+	xuexitongApi "github.com/yatori-dev/yatori-go-core/api/xuexitong"
+	lg "github.com/yatori-dev/yatori-go-core/utils/log"
+)
+
+// watchLive 在后台观看直播任务点，不阻塞章节中的其他任务点，课程结束前需等待wg，slots为本次运行所有账号共用的观看名额
+// 未开播的直播不在进程内等待，加入待解锁队列后直接返回，由守护模式到点重新运行
+func watchLive(wg *sync.WaitGroup, slots chan struct{}, user *config.Users, cache *xuexitongApi.XueXiTUserCache, live config.LiveSetting, courseItem *xuexitong.XueXiTCourse, knowledgeItem xuexitong.KnowledgeItem, p entity.PointLiveDto) {
+	point.PullLiveInfoAction(cache, &p)
+	if p.LiveStatusCode == 0 {
+		queueLive(user, cache, live, courseItem, knowledgeItem, p)
+		return
+	}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		slots <- struct{}{}
+		defer func() { <-slots }()
+		ExecuteLive(cache, courseItem, knowledgeItem, &p, live)
+	}()
+}
+
+// queueLive 未开播的直播加入待解锁队列，核心接口不提供开播时间，按重新检测间隔计算
+func queueLive(user *config.Users, cache *xuexitongApi.XueXiTUserCache, live config.LiveSetting, courseItem *xuexitong.XueXiTCourse, knowledgeItem xuexitong.KnowledgeItem, p entity.PointLiveDto) {
+	label := "【" + courseItem.CourseName + "】【" + knowledgeItem.Label + " " + knowledgeItem.Name + "】【" + p.Title + "】 >>> "
+	interval := live.RescheduleInterval()
+	if interval == 0 {
+		lg.Print(lg.INFO, "[", lg.Green, cache.Name, lg.Default, "] ", label, lg.Yellow, "该直播任务点还未开播，已自动跳过")
+		return
+	}
+	until := time.Now().Add(interval)
+	err := schedule.AddPending(schedule.PendingItem{Account: user.Account, Platform: user.AccountType, Course: courseItem.CourseName, Node: knowledgeItem.Name + " " + p.Title, Until: until})
+	if err != nil {
+		lg.Print(lg.INFO, "[", lg.Green, cache.Name, lg.Default, "] ", label, lg.BoldRed, "该直播任务点还未开播，加入待解锁队列失败，已跳过：", err.Error())
+		return
+	}
+	lg.Print(lg.INFO, "[", lg.Green, cache.Name, lg.Default, "] ", label, lg.Yellow, "该直播任务点还未开播，已加入待解锁队列，重新检测时间：", until.Format("2006-01-02 15:04:05"))
+}
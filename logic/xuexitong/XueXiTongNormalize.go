@@ -0,0 +1,49 @@
+package xuexitong
+
+import (
+	"yatori-go-console/utils/normalize"
+
+	"github.com/yatori-dev/yatori-go-core/api/entity"
+)
+
+// normalizeAnswers 按题型清理答案：选择题选项字母对应到选项，判断题同义词统一为“正确”“错误”，填空题去掉序号并按空拆分
+func normalizeAnswers(q entity.AnswerSetter, answers []string) []string {
+	switch v := q.(type) {
+	case *entity.ChoiceQue:
+		return normalize.Choice(answers, sortedOptions(v.Options))
+	case *entity.JudgeQue:
+		return normalize.Judge(answers, nil)
+	case *entity.FillQue:
+		return normalize.Blanks(answers, len(v.OpFromAnswer))
+	}
+	res := make([]string, 0, len(answers))
+	for _, answer := range answers {
+		res = append(res, normalize.Short(answer))
+	}
+	return res
+}
+
+// normalizeQuestion 提交前清理整份作业的答案，所有答案来源都会经过这里
+func normalizeQuestion(questionAction *entity.Question) {
+	for _, q := range allQuestions(questionAction) {
+		info := questionInfo(q)
+		if info.answers != nil {
+			if len(*info.answers) > 0 {
+				*info.answers = normalizeAnswers(q, *info.answers)
+			}
+			continue
+		}
+		if _, fill := q.(*entity.FillQue); fill {
+			keys := blankKeys(info.blanks)
+			for i, answer := range normalizeAnswers(q, blankAnswers(info.blanks)) {
+				if i < len(keys) && answer != "" {
+					info.blanks[keys[i]] = []string{answer}
+				}
+			}
+			continue
+		}
+		for key, answers := range info.blanks {
+			info.blanks[key] = normalizeAnswers(q, answers)
+		}
+	}
+}
@@ -1,8 +1,8 @@
 package xuexitong
 
 import (
+	"errors"
 	"fmt"
-	"log"
 	"os"
 	"strconv"
 	"strings"
@@ -10,6 +10,12 @@ import (
 	"time"
 	"yatori-go-console/config"
 	utils2 "yatori-go-console/utils"
+	"yatori-go-console/utils/health"
+	"yatori-go-console/utils/pacing"
+	"yatori-go-console/utils/result"
+	"yatori-go-console/utils/retry"
+	"yatori-go-console/utils/review"
+	"yatori-go-console/utils/schedule"
 
 	"github.com/thedevsaddam/gojsonq"
 	"github.com/yatori-dev/yatori-go-core/aggregation/xuexitong"
@@ -17,15 +23,11 @@ import (
 	"github.com/yatori-dev/yatori-go-core/api/entity"
 	xuexitongApi "github.com/yatori-dev/yatori-go-core/api/xuexitong"
 	"github.com/yatori-dev/yatori-go-core/que-core/aiq"
-	"github.com/yatori-dev/yatori-go-core/que-core/external"
 	"github.com/yatori-dev/yatori-go-core/utils"
 	lg "github.com/yatori-dev/yatori-go-core/utils/log"
 	"github.com/yatori-dev/yatori-go-core/utils/qutils"
 )
 
-var videosLock sync.WaitGroup //视频锁
-var usersLock sync.WaitGroup  //用户锁
-
 // 用于过滤学习通账号
 func FilterAccount(configData *config.JSONDataForConfig) []config.Users {
 	var users []config.Users //用于收集英华账号
@@ -57,9 +59,15 @@ func UserLoginOperation(users []config.Users) []*xuexitongApi.XueXiTUserCache {
 
 // 开始刷课模块
 func RunBrushOperation(setting config.Setting, users []config.Users, userCaches []*xuexitongApi.XueXiTUserCache) {
+	var usersLock sync.WaitGroup  //用户锁
+	var courseSlots chan struct{} //本次运行所有账号合计同时学习的课程名额，为nil时不限制
+	if limit := setting.Platforms["XUEXITONG"].MaxCourses; limit > 0 {
+		courseSlots = make(chan struct{}, limit)
+	}
+	liveSlots := make(chan struct{}, setting.Live.WorkerCount()) //本次运行所有账号合计同时观看直播的名额
 	for i, _ := range userCaches {
 		usersLock.Add(1)
-		go userBlock(setting, &users[i], userCaches[i])
+		go userBlock(setting, &users[i], userCaches[i], &usersLock, courseSlots, liveSlots)
 	}
 	usersLock.Wait()
 }
@@ -68,73 +76,116 @@ func RunBrushOperation(setting config.Setting, users []config.Users, userCaches
 // 以用户作为刷课单位的基本块
 var soundMut sync.Mutex
 
-func userBlock(setting config.Setting, user *config.Users, cache *xuexitongApi.XueXiTUserCache) {
+func userBlock(setting config.Setting, user *config.Users, cache *xuexitongApi.XueXiTUserCache, usersLock *sync.WaitGroup, courseSlots, liveSlots chan struct{}) {
+	defer usersLock.Done()
 	// list, err := xuexitong.XueXiTCourseDetailForCourseIdAction(cache, "261619055656961")
-	courseList, err := xuexitong.XueXiTPullCourseAction(cache)
+	courseList, err := retry.DoValue(retry.Get(user.AccountType), "["+cache.Name+"] ", func() ([]xuexitong.XueXiTCourse, error) {
+		return xuexitong.XueXiTPullCourseAction(cache)
+	})
 	if err != nil {
-		lg.Print(lg.INFO, "[", lg.Green, cache.Name, lg.Default, "] ", lg.Red, "拉取课程失败")
-		log.Fatal(err)
+		lg.Print(lg.INFO, "[", lg.Green, cache.Name, lg.Default, "] ", lg.Red, "拉取课程失败，该账号已跳过：", err.Error())
+		return
+	}
+	var courses sync.WaitGroup  //该账号正在学习的课程
+	var userSlots chan struct{} //该账号同时学习的课程名额，为nil时不限制
+	if limit := user.CoursesCustom.CourseLimit(); limit > 0 {
+		userSlots = make(chan struct{}, limit)
 	}
-	for _, course := range courseList {
-		videosLock.Add(1)
-		// fmt.Println(course)
-		if user.CoursesCustom.VideoModel == 3 {
+	for i := range courseList {
+		course := &courseList[i]
+		if user.CoursesCustom.VideoModel == 3 { //多课程模式，按名额同时学习多门课程
+			courses.Add(1)
 			go func() {
-				nodeListStudy(setting, user, cache, &course)
+				defer courses.Done()
+				release := acquireCourse(userSlots)
+				defer release()
+				studyCourse(setting, user, cache, courseSlots, liveSlots, course)
 			}()
 		} else {
-			nodeListStudy(setting, user, cache, &course)
+			studyCourse(setting, user, cache, courseSlots, liveSlots, course)
 		}
-
 	}
-	videosLock.Wait()
+	courses.Wait()
 	lg.Print(lg.INFO, "[", lg.Green, cache.Name, lg.Default, "] ", lg.Purple, "所有待学习课程学习完毕")
 	if setting.BasicSetting.CompletionTone == 1 { //如果声音提示开启，那么播放
 		soundMut.Lock()
 		utils2.PlayNoticeSound() //播放提示音
 		soundMut.Unlock()
 	}
-	usersLock.Done()
+}
+
+// acquireCourse 依次占用账号和全局的课程名额，返回释放方法
+func acquireCourse(slots ...chan struct{}) func() {
+	var held []chan struct{}
+	for _, slot := range slots {
+		if slot == nil {
+			continue
+		}
+		slot <- struct{}{}
+		held = append(held, slot)
+	}
+	return func() {
+		for _, slot := range held {
+			<-slot
+		}
+	}
+}
+
+// studyCourse 占用全局课程名额学习一门课程
+func studyCourse(setting config.Setting, user *config.Users, cache *xuexitongApi.XueXiTUserCache, courseSlots, liveSlots chan struct{}, courseItem *xuexitong.XueXiTCourse) {
+	release := acquireCourse(courseSlots)
+	defer release()
+	nodeListStudy(setting, user, cache, liveSlots, courseItem)
+}
+
+// pullCard 拉取任务点卡片信息，失败时按重试策略重试
+func pullCard(cache *xuexitongApi.XueXiTUserCache, classId, courseId, knowledgeId, cardIndex, cpi int) (interface{}, string, error) {
+	var card interface{}
+	var enc string
+	err := retry.Do(retry.Get("XUEXITONG"), "["+cache.Name+"] ", func() error {
+		var err error
+		card, enc, err = xuexitong.PageMobileChapterCardAction(cache, classId, courseId, knowledgeId, cardIndex, cpi)
+		return err
+	})
+	return card, enc, err
 }
 
 // 课程节点执行
-func nodeListStudy(setting config.Setting, user *config.Users, userCache *xuexitongApi.XueXiTUserCache, courseItem *xuexitong.XueXiTCourse) {
+func nodeListStudy(setting config.Setting, user *config.Users, userCache *xuexitongApi.XueXiTUserCache, liveSlots chan struct{}, courseItem *xuexitong.XueXiTCourse) {
 	//过滤课程---------------------------------
 	//排除指定课程
-	if len(user.CoursesCustom.ExcludeCourses) != 0 && config.CmpCourse(courseItem.CourseName, user.CoursesCustom.ExcludeCourses) {
-		videosLock.Done()
+	if len(user.CoursesCustom.ExcludeCourses) != 0 && config.CmpCourse(courseItem.CourseName, courseItem.CourseID, user.CoursesCustom.ExcludeCourses) {
 		return
 	}
 	//包含指定课程
-	if len(user.CoursesCustom.IncludeCourses) != 0 && !config.CmpCourse(courseItem.CourseName, user.CoursesCustom.IncludeCourses) {
-		videosLock.Done()
+	if len(user.CoursesCustom.IncludeCourses) != 0 && !config.CmpCourse(courseItem.CourseName, courseItem.CourseID, user.CoursesCustom.IncludeCourses) {
 		return
 	}
 	//如果课程还未开课则直接退出
 	if !courseItem.IsStart {
-		videosLock.Done()
 		lg.Print(lg.INFO, "[", lg.Green, userCache.Name, lg.Default, "] ", "[", courseItem.CourseName, "] ", lg.Blue, "该课程还未开课，已自动跳过该课程")
 		return
 	}
 	//如果该课程已经结束
 	if courseItem.State == 1 {
-		videosLock.Done()
 		lg.Print(lg.INFO, "[", lg.Green, userCache.Name, lg.Default, "] ", "[", courseItem.CourseName, "] ", lg.Blue, "该课程已经结束，已自动跳过该课程")
 		return
 	}
 
 	key, _ := strconv.Atoi(courseItem.Key)
-	action, _, err := xuexitong.PullCourseChapterAction(userCache, courseItem.Cpi, key) //获取对应章节信息
+	policy := retry.Get(user.AccountType)
+	action, err := retry.DoValue(policy, "["+userCache.Name+"] ", func() (xuexitong.ChaptersList, error) {
+		action, _, err := xuexitong.PullCourseChapterAction(userCache, courseItem.Cpi, key) //获取对应章节信息
+		return action, err
+	})
 
 	if err != nil {
-		if strings.Contains(err.Error(), "课程章节为空") {
-			lg.Print(lg.INFO, "[", lg.Green, userCache.Name, lg.Default, "] ", `[`, courseItem.CourseName, `] `, lg.BoldRed, "该课程章节为空已自动跳过")
-			videosLock.Done()
+		if result.Is(classifyError(err), result.NotStarted) {
+			lg.Print(lg.INFO, "[", lg.Green, userCache.Name, lg.Default, "] ", `[`, courseItem.CourseName, `] `, lg.BoldRed, "该课程章节为空或还未开放，已自动跳过")
 			return
 		}
 		lg.Print(lg.INFO, "[", lg.Green, userCache.Name, lg.Default, "] ", `[`, courseItem.CourseName, `] `, lg.BoldRed, "拉取章节信息接口访问异常，若需要继续可以配置中添加排除此异常课程。返回信息：", err.Error())
 
-		videosLock.Done()
 		return
 		//log.Fatal()
 	}
@@ -147,11 +198,12 @@ func nodeListStudy(setting config.Setting, user *config.Users, userCache *xuexit
 	courseId, _ := strconv.Atoi(courseItem.CourseID)
 	userId, _ := strconv.Atoi(userCache.UserID)
 	// 检测节点完成情况
-	pointAction, err := xuexitong.ChapterFetchPointAction(userCache, nodes, &action, key, userId, courseItem.Cpi, courseId)
+	pointAction, err := retry.DoValue(policy, "["+userCache.Name+"] ", func() (xuexitong.ChaptersList, error) {
+		return xuexitong.ChapterFetchPointAction(userCache, nodes, &action, key, userId, courseItem.Cpi, courseId)
+	})
 	if err != nil {
 		lg.Print(lg.INFO, "[", lg.Green, userCache.Name, lg.Default, "] ", `[`, courseItem.CourseName, `] `, lg.BoldRed, "探测节点完成情况接口访问异常，若需要继续可以配置中添加排除此异常课程。返回信息：", err.Error())
 		//log.Fatal()
-		videosLock.Done()
 		return
 	}
 	var isFinished = func(index int) bool {
@@ -170,15 +222,20 @@ func nodeListStudy(setting config.Setting, user *config.Users, userCache *xuexit
 	}
 
 	lg.Print(lg.INFO, "[", lg.Green, userCache.Name, lg.Default, "] ", "[", courseItem.CourseName, "] ", lg.Purple, "正在学习该课程")
+	var lives sync.WaitGroup //后台观看中的直播
 	for index := range nodes {
 		if isFinished(index) { //如果完成了的那么直接跳过
 			continue
 		}
-		_, fetchCards, err1 := xuexitong.ChapterFetchCardsAction(userCache, &action, nodes, index, courseId, key, courseItem.Cpi)
+		schedule.WaitWindow(userCache.Name) //不在学习时间窗口内则在此暂停
+		fetchCards, err1 := retry.DoValue(policy, "["+userCache.Name+"] ", func() ([]entity.PointDto, error) {
+			_, fetchCards, err := xuexitong.ChapterFetchCardsAction(userCache, &action, nodes, index, courseId, key, courseItem.Cpi)
+			return fetchCards, err
+		})
 		if err1 != nil {
 			lg.Print(lg.INFO, "[", lg.Green, userCache.Name, lg.Default, "] ", `[`, courseItem.CourseName, `] `, lg.BoldRed, "无法正常拉取卡片信息，请联系作者查明情况,报错信息：", err1.Error())
 			//log.Fatal(err1)
-			videosLock.Done()
+			lives.Wait()
 			return
 		}
 		videoDTOs, workDTOs, documentDTOs, hyperlinkDTOs, liveDTOs, bbsDTOs := entity.ParsePointDto(fetchCards)
@@ -187,12 +244,12 @@ func nodeListStudy(setting config.Setting, user *config.Users, userCache *xuexit
 			continue
 		}
 		// 视屏类型
-		if videoDTOs != nil && user.CoursesCustom.VideoModel != 0 {
+		if videoDTOs != nil && user.CoursesCustom.TaskEnabled(config.TaskVideo) {
 			for _, videoDTO := range videoDTOs {
-				card, enc, err2 := xuexitong.PageMobileChapterCardAction(
-					userCache, key, courseId, videoDTO.KnowledgeID, videoDTO.CardIndex, courseItem.Cpi)
+				card, enc, err2 := pullCard(userCache, key, courseId, videoDTO.KnowledgeID, videoDTO.CardIndex, courseItem.Cpi)
 				if err2 != nil {
-					log.Fatal(err2)
+					lg.Print(lg.INFO, "[", lg.Green, userCache.Name, lg.Default, "] ", `[`, courseItem.CourseName, `] `, `[`, pointAction.Knowledge[index].Name, `] `, lg.BoldRed, "拉取任务点卡片失败，已跳过该任务点：", err2.Error())
+					continue
 				}
 				videoDTO.AttachmentsDetection(card)
 
@@ -215,16 +272,16 @@ func nodeListStudy(setting config.Setting, user *config.Users, userCache *xuexit
 					ExecuteVideo2(userCache, courseItem, pointAction.Knowledge[index], &videoDTO, key, courseItem.Cpi) //多课程模式
 				}
 
-				time.Sleep(10 * time.Second)
+				pacing.Get("XUEXITONG").WaitGap() //任务点之间的间隔
 			}
 		}
 		// 文档类型
-		if documentDTOs != nil {
+		if documentDTOs != nil && user.CoursesCustom.TaskEnabled(config.TaskDocument) {
 			for _, documentDTO := range documentDTOs {
-				card, _, err2 := xuexitong.PageMobileChapterCardAction(
-					userCache, key, courseId, documentDTO.KnowledgeID, documentDTO.CardIndex, courseItem.Cpi)
+				card, _, err2 := pullCard(userCache, key, courseId, documentDTO.KnowledgeID, documentDTO.CardIndex, courseItem.Cpi)
 				if err2 != nil {
-					log.Fatal(err2)
+					lg.Print(lg.INFO, "[", lg.Green, userCache.Name, lg.Default, "] ", `[`, courseItem.CourseName, `] `, `[`, pointAction.Knowledge[index].Name, `] `, lg.BoldRed, "拉取任务点卡片失败，已跳过该任务点：", err2.Error())
+					continue
 				}
 				documentDTO.AttachmentsDetection(card)
 				//如果不是任务或者说该任务已完成，那么直接跳过
@@ -232,30 +289,22 @@ func nodeListStudy(setting config.Setting, user *config.Users, userCache *xuexit
 					continue
 				}
 				ExecuteDocument(userCache, courseItem, pointAction.Knowledge[index], &documentDTO)
-				time.Sleep(5 * time.Second)
+				pacing.Get("XUEXITONG").WaitGap() //任务点之间的间隔
 			}
 		}
 
 		//作业刷取
-		if workDTOs != nil && user.CoursesCustom.AutoExam != 0 {
+		if workDTOs != nil && user.CoursesCustom.TaskEnabled(config.TaskWork) {
 
-			if user.CoursesCustom.AutoExam == 1 { //检测AI可用性
-				err2 := aiq.AICheck(setting.AiSetting.AiUrl, setting.AiSetting.Model, setting.AiSetting.APIKEY, setting.AiSetting.AiType)
-				if err2 != nil {
-					lg.Print(lg.INFO, lg.BoldRed, "<"+setting.AiSetting.AiType+">", "AI不可用，错误信息："+err2.Error())
-					os.Exit(0)
-				}
-			} else if user.CoursesCustom.AutoExam == 2 { // 检测外挂题库可用性
-				err2 := external.CheckApiQueRequest(setting.ApiQueSetting.Url, 3, nil)
-				if err2 != nil {
-					lg.Print(lg.INFO, lg.BoldRed, "外挂题库不可用，错误信息："+err2.Error())
-					os.Exit(0)
-				}
+			//答题依赖已在启动时检测，不可用时跳过作业继续学习
+			if ok, err2 := health.Ready(user.CoursesCustom.AnswerSources()); !ok {
+				lg.Print(lg.INFO, "[", lg.Green, userCache.Name, lg.Default, "] ", "【", courseItem.CourseName, "】", lg.BoldRed, "答案来源不可用，跳过该章节作业：", err2.Error())
+				workDTOs = nil
 			}
 
 			for _, workDTO := range workDTOs {
 				//以手机端拉取章节卡片数据
-				mobileCard, _, _ := xuexitong.PageMobileChapterCardAction(userCache, key, courseId, workDTO.KnowledgeID, workDTO.CardIndex, courseItem.Cpi)
+				mobileCard, _, _ := pullCard(userCache, key, courseId, workDTO.KnowledgeID, workDTO.CardIndex, courseItem.Cpi)
 				flag, _ := workDTO.AttachmentsDetection(mobileCard)
 				questionAction := xuexitong.ParseWorkQuestionAction(userCache, &workDTO)
 				if !flag {
@@ -276,122 +325,59 @@ func nodeListStudy(setting config.Setting, user *config.Users, userCache *xuexit
 		}
 
 		//外链任务点刷取
-		if hyperlinkDTOs != nil {
+		if hyperlinkDTOs != nil && user.CoursesCustom.TaskEnabled(config.TaskHyperlink) {
 			for _, hyperlinkDTO := range hyperlinkDTOs {
-				card, _, err2 := xuexitong.PageMobileChapterCardAction(
-					userCache, key, courseId, hyperlinkDTO.KnowledgeID, hyperlinkDTO.CardIndex, courseItem.Cpi)
+				card, _, err2 := pullCard(userCache, key, courseId, hyperlinkDTO.KnowledgeID, hyperlinkDTO.CardIndex, courseItem.Cpi)
 				if err2 != nil {
-					log.Fatal(err2)
+					lg.Print(lg.INFO, "[", lg.Green, userCache.Name, lg.Default, "] ", `[`, courseItem.CourseName, `] `, `[`, pointAction.Knowledge[index].Name, `] `, lg.BoldRed, "拉取任务点卡片失败，已跳过该任务点：", err2.Error())
+					continue
 				}
 				hyperlinkDTO.AttachmentsDetection(card)
 
 				ExecuteHyperlink(userCache, courseItem, pointAction.Knowledge[index], &hyperlinkDTO)
-				time.Sleep(5 * time.Second)
+				pacing.Get("XUEXITONG").WaitGap() //任务点之间的间隔
 			}
 		}
 		// 直播任务点刷取
-		if liveDTOs != nil {
+		if liveDTOs != nil && user.CoursesCustom.TaskEnabled(config.TaskLive) {
 			for _, liveDTO := range liveDTOs {
-				card, _, err2 := xuexitong.PageMobileChapterCardAction(
-					userCache, key, courseId, liveDTO.KnowledgeID, liveDTO.CardIndex, courseItem.Cpi)
+				card, _, err2 := pullCard(userCache, key, courseId, liveDTO.KnowledgeID, liveDTO.CardIndex, courseItem.Cpi)
 				if err2 != nil {
-					log.Fatal(err2)
+					lg.Print(lg.INFO, "[", lg.Green, userCache.Name, lg.Default, "] ", `[`, courseItem.CourseName, `] `, `[`, pointAction.Knowledge[index].Name, `] `, lg.BoldRed, "拉取任务点卡片失败，已跳过该任务点：", err2.Error())
+					continue
 				}
 				liveDTO.AttachmentsDetection(card)
 				if !liveDTO.IsJob { //不是任务点或者已经是完成的任务点直接退出
 					continue
 				}
-				ExecuteLive(userCache, courseItem, pointAction.Knowledge[index], &liveDTO)
-				time.Sleep(5 * time.Second)
+				watchLive(&lives, liveSlots, user, userCache, setting.Live, courseItem, pointAction.Knowledge[index], liveDTO) //直播在后台观看，同时继续其他任务点
 			}
 		}
 
 		//讨论任务点刷取
-		if bbsDTOs != nil && user.CoursesCustom.AutoExam != 0 {
-			if user.CoursesCustom.AutoExam == 1 { //检测AI可用性
-				err2 := aiq.AICheck(setting.AiSetting.AiUrl, setting.AiSetting.Model, setting.AiSetting.APIKEY, setting.AiSetting.AiType)
-				if err2 != nil {
-					lg.Print(lg.INFO, lg.BoldRed, "<"+setting.AiSetting.AiType+">", "AI不可用，错误信息："+err2.Error())
-					os.Exit(0)
-				}
-			} else if user.CoursesCustom.AutoExam == 2 { // 检测外挂题库可用性
-				err2 := external.CheckApiQueRequest(setting.ApiQueSetting.Url, 3, nil)
-				if err2 != nil {
-					lg.Print(lg.INFO, lg.BoldRed, "外挂题库不可用，错误信息："+err2.Error())
-					os.Exit(0)
-				}
+		if bbsDTOs != nil && user.CoursesCustom.TaskEnabled(config.TaskBBS) {
+			//需要AI回复时先确认AI可用，模板回复不受影响
+			if ok, err2 := health.Ready([]string{health.AI}); user.CoursesCustom.BBSUseAI() && !ok {
+				lg.Print(lg.INFO, "[", lg.Green, userCache.Name, lg.Default, "] ", "【", courseItem.CourseName, "】", lg.BoldRed, "AI不可用，跳过该章节讨论：", err2.Error())
+				bbsDTOs = nil
 			}
 			for _, bbsDTO := range bbsDTOs {
-				card, _, err2 := xuexitong.PageMobileChapterCardAction(
-					userCache, key, courseId, bbsDTO.KnowledgeID, bbsDTO.CardIndex, courseItem.Cpi)
+				card, _, err2 := pullCard(userCache, key, courseId, bbsDTO.KnowledgeID, bbsDTO.CardIndex, courseItem.Cpi)
 				if err2 != nil {
-					log.Fatal(err2)
+					lg.Print(lg.INFO, "[", lg.Green, userCache.Name, lg.Default, "] ", `[`, courseItem.CourseName, `] `, `[`, pointAction.Knowledge[index].Name, `] `, lg.BoldRed, "拉取任务点卡片失败，已跳过该任务点：", err2.Error())
+					continue
 				}
 				bbsDTO.AttachmentsDetection(card)
 				if !bbsDTO.IsJob { //不是任务点或者已经是完成的任务点直接退出
 					continue
 				}
-				ExecuteBBS(userCache, setting, courseItem, pointAction.Knowledge[index], &bbsDTO)
-				time.Sleep(5 * time.Second)
+				ExecuteBBS(userCache, user, setting, courseItem, pointAction.Knowledge[index], &bbsDTO)
+				pacing.Get("XUEXITONG").WaitGap() //任务点之间的间隔
 			}
 		}
 	}
+	lives.Wait() //等待后台观看的直播结束
 	lg.Print(lg.INFO, "[", lg.Green, userCache.Name, lg.Default, "] ", "[", courseItem.CourseName, "] ", lg.Purple, "课程学习完毕")
-	videosLock.Done()
-}
-
-// 检测答题是否有留空
-func CheckAnswerIsAvoid(choices []entity.ChoiceQue, judges []entity.JudgeQue, fills []entity.FillQue, shorts []entity.ShortQue) bool {
-	for _, choice := range choices {
-		resStatus := true
-		if choice.Answers != nil {
-			candidateSelects := []string{} //待选
-			for _, option := range choice.Options {
-				candidateSelects = append(candidateSelects, option)
-			}
-			for _, answer := range choice.Answers {
-				var sortArray []qutils.Co = qutils.SimilarityArrayAndSort(answer, candidateSelects)
-				if sortArray[0].Score >= 0.9 {
-					resStatus = false
-				}
-			}
-
-			//fmt.Sprintf("D -> 以上A B C正确。")
-			if resStatus { //如果当前题目为留空态
-				return true
-			}
-		} else {
-			return true
-		}
-	}
-	for _, judge := range judges {
-		resStatus := true
-		if judge.Answers != nil {
-			for _, answer := range judge.Answers {
-				for _, option := range judge.Options {
-					if answer == option || answer == "错误" || answer == "正确" { //只需检测能够映射对应选项即可
-						resStatus = false
-					}
-				}
-			}
-			if resStatus { //如果当前题目为留空态
-				return true
-			}
-		} else {
-			return true
-		}
-	}
-	for _, fill := range fills {
-		if fill.OpFromAnswer == nil || len(fill.OpFromAnswer) <= 0 {
-			return true
-		}
-	}
-	for _, short := range shorts {
-		if short.OpFromAnswer == nil || len(short.OpFromAnswer) <= 0 {
-			return true
-		}
-	}
-	return false
 }
 
 // 答案修正匹配
@@ -427,7 +413,7 @@ func AnswerFixedPattern(choices []entity.ChoiceQue, judges []entity.JudgeQue, fi
 // 常规刷视频逻辑
 func ExecuteVideo2(cache *xuexitongApi.XueXiTUserCache, courseItem *xuexitong.XueXiTCourse, knowledgeItem xuexitong.KnowledgeItem, p *entity.PointVideoDto, key, courseCpi int) {
 
-	if state, _ := xuexitong.VideoDtoFetchAction(cache, p); state {
+	if err := fetchVideo(cache, p); err == nil {
 
 		var playingTime = p.PlayTime
 		if p.IsPassed == false && p.PlayTime == p.Duration {
@@ -435,12 +421,15 @@ func ExecuteVideo2(cache *xuexitongApi.XueXiTUserCache, courseItem *xuexitong.Xu
 		}
 		var overTime = 0
 		//secList := []int{58} //停滞时间随机表
-		selectSec := 58                     //默认60s
+		pace := pacing.Get("XUEXITONG")
+		selectSec := pace.Step              //每次提交推进的学时，默认58s
 		extendSec := 5                      //过超提交停留时间
 		limitTime := max(500, p.Duration/2) //过超时间最大限制
 		mode := 1                           //0为Web模式，1为手机模式
+		bo := retry.NewBackoff("XUEXITONG")
 		//flag := 0
 		for {
+			schedule.WaitWindow(cache.Name) //不在学习时间窗口内则在此暂停
 			var playReport string
 			var err error
 			//selectSec = secList[rand.Intn(len(secList))] //随机选择时间
@@ -510,7 +499,10 @@ func ExecuteVideo2(cache *xuexitongApi.XueXiTUserCache, courseItem *xuexitong.Xu
 					continue
 				}
 				if strings.Contains(err.Error(), "failed to fetch video, status code: 404") { //触发404
-					time.Sleep(10 * time.Second)
+					if !bo.Wait(classifyError(err)) {
+						lg.Print(lg.INFO, "[", lg.Green, cache.Name, lg.Default, "] ", "【", courseItem.CourseName, "】", "【", knowledgeItem.Label, " ", knowledgeItem.Name, "】", "【", p.Title, "】 >>> ", lg.BoldRed, "多次提交学时失败，该视频已标记为失败并跳过")
+						break
+					}
 					continue
 				}
 			}
@@ -519,6 +511,7 @@ func ExecuteVideo2(cache *xuexitongApi.XueXiTUserCache, courseItem *xuexitong.Xu
 				lg.Print(lg.INFO, `[`, cache.Name, `] `, "【", courseItem.CourseName, "】", "【", knowledgeItem.Label, " ", knowledgeItem.Name, "】", "【", p.Title, "】", lg.BoldRed, "提交学时接口访问异常，返回信息：", playReport, err.Error())
 				break
 			}
+			bo.Reset()
 			//阈值超限提交
 			outTimeMsg := gojsonq.New().JSONString(playReport).Find("OutTimeMsg")
 			if outTimeMsg != nil {
@@ -560,25 +553,38 @@ func ExecuteVideo2(cache *xuexitongApi.XueXiTUserCache, courseItem *xuexitong.Xu
 				time.Sleep(time.Duration(extendSec) * time.Second)
 			} else { //正常计时逻辑
 				playingTime = playingTime + selectSec
-				time.Sleep(time.Duration(selectSec) * time.Second)
+				pace.Wait()
 			}
 		}
 	} else {
-		log.Fatal("视频解析失败")
+		lg.Print(lg.INFO, "[", lg.Green, cache.Name, lg.Default, "] ", "【", courseItem.CourseName, "】", "【", knowledgeItem.Label, " ", knowledgeItem.Name, "】", "【", p.Title, "】 >>> ", lg.BoldRed, "视频解析失败，该视频已跳过：", err.Error())
 	}
 }
 
+// fetchVideo 拉取视频信息，失败时按重试策略重试
+func fetchVideo(cache *xuexitongApi.XueXiTUserCache, p *entity.PointVideoDto) error {
+	return retry.Do(retry.Get("XUEXITONG"), "["+cache.Name+"] ", func() error {
+		state, err := xuexitong.VideoDtoFetchAction(cache, p)
+		if err == nil && !state {
+			err = errors.New("视频解析失败")
+		}
+		return err
+	})
+}
+
 // 58倍速模式刷视频逻辑
 func ExecuteVideoQuickSpeed(cache *xuexitongApi.XueXiTUserCache, courseItem *xuexitong.XueXiTCourse, knowledgeItem xuexitong.KnowledgeItem, p *entity.PointVideoDto, key, courseCpi int) {
-	if state, _ := xuexitong.VideoDtoFetchAction(cache, p); state {
+	if err := fetchVideo(cache, p); err == nil {
 		var playingTime = p.PlayTime
 		if p.IsPassed == false && p.PlayTime == p.Duration {
 			playingTime = 0
 		}
 		var overTime = 0
-		selectSec := 58
+		selectSec := pacing.Get("XUEXITONG").Step
 		mode := 1 //0为web模式，1为手机模式
+		bo := retry.NewBackoff("XUEXITONG")
 		for {
+			schedule.WaitWindow(cache.Name) //不在学习时间窗口内则在此暂停
 			var playReport string
 			var err error
 			if playingTime != p.Duration {
@@ -615,10 +621,10 @@ func ExecuteVideoQuickSpeed(cache *xuexitongApi.XueXiTUserCache, courseItem *xue
 					}
 					cid, _ := strconv.Atoi(p.CourseID)
 					time.Sleep(3 * time.Second)
-					card, enc, err := xuexitong.PageMobileChapterCardAction(
-						cache, key, cid, p.KnowledgeID, p.CardIndex, courseCpi)
+					card, enc, err := pullCard(cache, key, cid, p.KnowledgeID, p.CardIndex, courseCpi)
 					if err != nil {
-						log.Fatal(err)
+						lg.Print(lg.INFO, "[", lg.Green, cache.Name, lg.Default, "] ", "【", courseItem.CourseName, "】", "【", knowledgeItem.Label, " ", knowledgeItem.Name, "】", "【", p.Title, "】 >>> ", lg.BoldRed, "绕过人脸后获取卡片数据失败，已自动绕过该视屏", err.Error())
+						break
 					}
 					p.AttachmentsDetection(card)
 					p.Enc = enc
@@ -637,8 +643,11 @@ func ExecuteVideoQuickSpeed(cache *xuexitongApi.XueXiTUserCache, courseItem *xue
 					}
 					continue
 				}
-				if strings.Contains(err.Error(), "failed to fetch video, status code: 404") { //触发202立即使用人脸检测
-					time.Sleep(10 * time.Second)
+				if strings.Contains(err.Error(), "failed to fetch video, status code: 404") { //触发404
+					if !bo.Wait(classifyError(err)) {
+						lg.Print(lg.INFO, "[", lg.Green, cache.Name, lg.Default, "] ", "【", courseItem.CourseName, "】", "【", knowledgeItem.Label, " ", knowledgeItem.Name, "】", "【", p.Title, "】 >>> ", lg.BoldRed, "多次提交学时失败，该视频已标记为失败并跳过")
+						break
+					}
 					continue
 				}
 			}
@@ -646,6 +655,7 @@ func ExecuteVideoQuickSpeed(cache *xuexitongApi.XueXiTUserCache, courseItem *xue
 				lg.Print(lg.INFO, `[`, cache.Name, `] `, "【", courseItem.CourseName, "】", "【", knowledgeItem.Label, " ", knowledgeItem.Name, "】", "【", p.Title, "】", lg.BoldRed, "提交学时接口访问异常，返回信息：", playReport, err.Error())
 				break
 			}
+			bo.Reset()
 			//阈值超限提交
 			outTimeMsg := gojsonq.New().JSONString(playReport).Find("OutTimeMsg")
 			if outTimeMsg != nil {
@@ -677,7 +687,7 @@ func ExecuteVideoQuickSpeed(cache *xuexitongApi.XueXiTUserCache, courseItem *xue
 			}
 		}
 	} else {
-		log.Fatal("视频解析失败")
+		lg.Print(lg.INFO, "[", lg.Green, cache.Name, lg.Default, "] ", "【", courseItem.CourseName, "】", "【", knowledgeItem.Label, " ", knowledgeItem.Name, "】", "【", p.Title, "】 >>> ", lg.BoldRed, "视频解析失败，该视频已跳过：", err.Error())
 	}
 }
 
@@ -715,16 +725,8 @@ func ExecuteHyperlink(cache *xuexitongApi.XueXiTUserCache, courseItem *xuexitong
 	}
 }
 
-// 常规直播任务处理
-func ExecuteLive(cache *xuexitongApi.XueXiTUserCache, courseItem *xuexitong.XueXiTCourse, knowledgeItem xuexitong.KnowledgeItem, p *entity.PointLiveDto) {
-	point.PullLiveInfoAction(cache, p)
-	var passValue float64 = 90
-
-	//如果该直播还未开播
-	if p.LiveStatusCode == 0 {
-		lg.Print(lg.INFO, "[", lg.Green, cache.Name, lg.Default, "] ", "【", courseItem.CourseName, "】", "【", knowledgeItem.Label, " ", knowledgeItem.Name, "】", "【", p.Title, "】 >>> ", lg.Yellow, "该直播任务点还未开播，已自动跳过")
-		return
-	}
+// 常规直播任务处理，调用前需确认已开播，观看超过最长观看时间仍未完成时放弃
+func ExecuteLive(cache *xuexitongApi.XueXiTUserCache, courseItem *xuexitong.XueXiTCourse, knowledgeItem xuexitong.KnowledgeItem, p *entity.PointLiveDto, live config.LiveSetting) {
 	relationReport, err2 := point.LiveCreateRelationAction(cache, p)
 	if err2 != nil {
 		lg.Print(lg.INFO, `[`, cache.Name, `] `, "【", courseItem.CourseName, "】", "【", knowledgeItem.Label, " ", knowledgeItem.Name, "】", "【", p.Title, "】", lg.BoldRed, "直播任务点建立联系接口访问异常，返回信息：", relationReport, err2.Error())
@@ -732,14 +734,11 @@ func ExecuteLive(cache *xuexitongApi.XueXiTUserCache, courseItem *xuexitong.XueX
 		lg.Print(lg.INFO, `[`, cache.Name, `] `, "【", courseItem.CourseName, "】", "【", knowledgeItem.Label, " ", knowledgeItem.Name, "】", "【", p.Title, "】", lg.Green, "直播任务点建立联系成功，返回信息：", relationReport)
 	}
 
+	deadline := time.Now().Add(live.WatchLimit())
 	for {
 		report, err := point.ExecuteLive(cache, p)
 
 		point.PullLiveInfoAction(cache, p) //更新直播节点结构体进度
-		if err != nil {
-			lg.Print(lg.INFO, `[`, cache.Name, `] `, "【", courseItem.CourseName, "】", "【", knowledgeItem.Label, " ", knowledgeItem.Name, "】", "【", p.Title, "】", lg.BoldRed, "直播任务点学习提交接口访问异常，返回信息：", report, err.Error())
-		}
-
 		if strings.Contains(report, "@success") {
 			lg.Print(lg.INFO, "[", lg.Green, cache.Name, lg.Default, "] ", "【", courseItem.CourseName, "】", "【", knowledgeItem.Label, " ", knowledgeItem.Name, "】", "【", p.Title, "】 >>> ", "直播任务点状态：", lg.Green, report, lg.Default, "，直播观看进度：", lg.Green, fmt.Sprintf("%.2f", p.VideoCompletePercent), "%")
 		} else {
@@ -749,135 +748,136 @@ func ExecuteLive(cache *xuexitongApi.XueXiTUserCache, courseItem *xuexitong.XueX
 				lg.Print(lg.INFO, `[`, cache.Name, `] `, "【", courseItem.CourseName, "】", "【", knowledgeItem.Label, " ", knowledgeItem.Name, "】", "【", p.Title, "】", lg.BoldRed, "直播任务点学习提交接口访问异常，返回信息：", report)
 			}
 		}
-		if p.VideoCompletePercent >= passValue {
+		if p.VideoCompletePercent >= live.Pass() {
 			lg.Print(lg.INFO, "[", lg.Green, cache.Name, lg.Default, "] ", "【", courseItem.CourseName, "】", "【", knowledgeItem.Label, " ", knowledgeItem.Name, "】", "【", p.Title, "】 >>> ", lg.Green, "直播任务点已完成")
 			return
 		}
-		time.Sleep(30 * time.Second)
+		if time.Now().After(deadline) {
+			lg.Print(lg.INFO, "[", lg.Green, cache.Name, lg.Default, "] ", "【", courseItem.CourseName, "】", "【", knowledgeItem.Label, " ", knowledgeItem.Name, "】", "【", p.Title, "】 >>> ", lg.Yellow, "直播观看时间已超过", strconv.Itoa(int(live.WatchLimit()/time.Minute)), "分钟仍未完成，已放弃")
+			return
+		}
+		time.Sleep(live.PollInterval())
 	}
 }
 
 // 常规讨论任务处理
-func ExecuteBBS(cache *xuexitongApi.XueXiTUserCache, setting config.Setting, courseItem *xuexitong.XueXiTCourse, knowledgeItem xuexitong.KnowledgeItem, bbsDto *entity.PointBBsDto) {
+func ExecuteBBS(cache *xuexitongApi.XueXiTUserCache, user *config.Users, setting config.Setting, courseItem *xuexitong.XueXiTCourse, knowledgeItem xuexitong.KnowledgeItem, bbsDto *entity.PointBBsDto) {
 	bbsTopic, err := point.PullBbsInfoAction(cache, bbsDto) //拉取相关数据
 	if err != nil {
 		fmt.Println(err)
+		return
 	}
-	report, err := bbsTopic.AIAnswer(cache, bbsDto, setting.AiSetting.AiUrl, setting.AiSetting.Model, setting.AiSetting.AiType, setting.AiSetting.APIKEY)
-	if gojsonq.New().JSONString(report).Find("status") == nil || err != nil || gojsonq.New().JSONString(report).Find("status") == false {
-		if err == nil {
-			lg.Print(lg.INFO, `[`, cache.Name, `] `, "【", courseItem.CourseName, "】", "【", knowledgeItem.Label, " ", knowledgeItem.Name, "】", "【", bbsTopic.Title, "】", lg.BoldRed, "外链任务点学习提交接口访问异常，返回信息：", report)
-		} else {
-			lg.Print(lg.INFO, `[`, cache.Name, `] `, "【", courseItem.CourseName, "】", "【", knowledgeItem.Label, " ", knowledgeItem.Name, "】", "【", bbsTopic.Title, "】", lg.BoldRed, "外链任务点学习提交接口访问异常，返回信息：", report, err.Error())
-		}
+	if review.Pending(user.AccountType, user.Account, "bbs_"+bbsTopic.Uuid) { //还在等待人工审核的不再生成回复
+		lg.Print(lg.INFO, "[", lg.Green, cache.Name, lg.Default, "] ", "【", courseItem.CourseName, "】", "【", knowledgeItem.Label, " ", knowledgeItem.Name, "】", "【", bbsTopic.Title, "】", lg.Yellow, "讨论回复还在等待人工审核，已跳过，确认后使用 review apply 发布")
+		return
 	}
-
-	if gojsonq.New().JSONString(report).Find("status").(bool) {
-		lg.Print(lg.INFO, "[", lg.Green, cache.Name, lg.Default, "] ", "【", courseItem.CourseName, "】", "【", knowledgeItem.Label, " ", knowledgeItem.Name, "】", "【", bbsTopic.Title, "】 >>> ", "讨论任务点状态：", lg.Green, lg.Green, gojsonq.New().JSONString(report).Find("msg").(string), lg.Default, " ")
+	reply, err := bbsReply(cache, user, setting.AiSetting, courseItem.CourseName, bbsTopic)
+	if err != nil {
+		lg.Print(lg.INFO, "[", lg.Green, cache.Name, lg.Default, "] ", "【", courseItem.CourseName, "】", "【", knowledgeItem.Label, " ", knowledgeItem.Name, "】", "【", bbsTopic.Title, "】", lg.BoldRed, "讨论回复生成失败，已跳过：", err.Error())
+		return
+	}
+	//审核方式只写入待审核文件，人工确认后再发布
+	if user.CoursesCustom.BBSMode() == config.BBSModeReview {
+		saveBBSReview(cache, user, courseItem, knowledgeItem, bbsTopic, bbsDto, reply)
+		return
 	}
+	report, err := postBBS(cache, bbsTopic, bbsDto, reply)
+	if err != nil {
+		lg.Print(lg.INFO, "[", lg.Green, cache.Name, lg.Default, "] ", "【", courseItem.CourseName, "】", "【", knowledgeItem.Label, " ", knowledgeItem.Name, "】", "【", bbsTopic.Title, "】", lg.BoldRed, "讨论任务点提交接口访问异常，返回信息：", err.Error())
+		return
+	}
+	msg, _ := gojsonq.New().JSONString(report).Find("msg").(string)
+	lg.Print(lg.INFO, "[", lg.Green, cache.Name, lg.Default, "] ", "【", courseItem.CourseName, "】", "【", knowledgeItem.Label, " ", knowledgeItem.Name, "】", "【", bbsTopic.Title, "】 >>> ", "讨论任务点状态：", lg.Green, msg, lg.Default, " ")
 }
 
 // 作业处理逻辑
 func WorkAction(userCache *xuexitongApi.XueXiTUserCache, user *config.Users, setting config.Setting, courseItem *xuexitong.XueXiTCourse, knowledgeItem xuexitong.KnowledgeItem, questionAction entity.Question) {
+	if review.Pending(user.AccountType, user.Account, "work_"+questionAction.WorkId) { //还在等待人工审核的不再作答，避免覆盖人工修改中的答案
+		lg.Print(lg.INFO, "[", lg.Green, userCache.Name, lg.Default, "] ", "【", courseItem.CourseName, "】", "【", knowledgeItem.Label, " ", knowledgeItem.Name, "】", "【", questionAction.Title, "】", lg.Yellow, "答案还在等待人工审核，已跳过，确认后使用 review apply 提交")
+		return
+	}
 	if user.CoursesCustom.AutoExam == 1 {
 		lg.Print(lg.INFO, "[", lg.Green, userCache.Name, lg.Default, "] ", "<"+setting.AiSetting.AiType+">", lg.Default, "【"+courseItem.CourseName+"】 ", "【", knowledgeItem.Label, " ", knowledgeItem.Name, "】", "【", questionAction.Title, "】", lg.Yellow, "正在AI自动写章节作业...")
 	} else {
 		lg.Print(lg.INFO, "[", lg.Green, userCache.Name, lg.Default, "] ", lg.Default, "【"+courseItem.CourseName+"】 ", "【", knowledgeItem.Label, " ", knowledgeItem.Name, "】", "【", questionAction.Title, "】", lg.Yellow, "正在外挂题库自动写章节作业...")
 	}
 
+	bankHits := 0                  //本地题库命中数
+	disputed := false              //共识模式下是否有题目各AI答案不一致且需人工审核
+	sources := map[string]string{} //每道题的答案来源，key为题目ID
+	var paused error               //AI用量超出上限且配置为暂停答题
+	//按答案来源顺序逐题作答
+	answer := func(q entity.AnswerSetter, message aiq.AIChatMessages) {
+		if paused != nil {
+			return
+		}
+		message = questionPrompt(userCache, user, setting.AiSetting, courseItem.CourseName, questionAction.Title, q, message)
+		source, review, err := chainAnswer(userCache, user, setting, questionAction.Title, q, message)
+		if err != nil {
+			paused = err
+			return
+		}
+		if strings.HasPrefix(source, config.AnswerBank) {
+			bankHits++
+		}
+		sources[questionInfo(q).qid] = source
+		disputed = disputed || review
+	}
 	//选择题
 	for i := range questionAction.Choice {
 		q := &questionAction.Choice[i] // 获取对应选项
-		switch user.CoursesCustom.AutoExam {
-		case 1:
-			message := xuexitong.AIProblemMessage(questionAction.Title, q.Type.String(), entity.ExamTurn{
-				XueXChoiceQue: *q,
-			})
-
-			aiSetting := setting.AiSetting //获取AI设置
-			q.AnswerAIGet(userCache.UserID, aiSetting.AiUrl, aiSetting.Model, aiSetting.AiType, message, aiSetting.APIKEY)
-		case 2:
-			q.AnswerExternalGet(setting.ApiQueSetting.Url)
-		}
-
+		answer(q, xuexitong.AIProblemMessage(questionAction.Title, q.Type.String(), entity.ExamTurn{
+			XueXChoiceQue: *q,
+		}))
 	}
 	//判断题
 	for i := range questionAction.Judge {
 		q := &questionAction.Judge[i] // 获取对应选项
-		switch user.CoursesCustom.AutoExam {
-		case 1:
-			message := xuexitong.AIProblemMessage(questionAction.Title, q.Type.String(), entity.ExamTurn{
-				XueXJudgeQue: *q,
-			})
-
-			aiSetting := setting.AiSetting //获取AI设置
-			q.AnswerAIGet(userCache.UserID, aiSetting.AiUrl, aiSetting.Model, aiSetting.AiType, message, aiSetting.APIKEY)
-		case 2:
-			q.AnswerExternalGet(setting.ApiQueSetting.Url)
-		}
-
+		answer(q, xuexitong.AIProblemMessage(questionAction.Title, q.Type.String(), entity.ExamTurn{
+			XueXJudgeQue: *q,
+		}))
 	}
 	//填空题
 	for i := range questionAction.Fill {
 		q := &questionAction.Fill[i] // 获取对应选项
-		switch user.CoursesCustom.AutoExam {
-		case 1:
-			message := xuexitong.AIProblemMessage(questionAction.Title, q.Type.String(), entity.ExamTurn{
-				XueXFillQue: *q,
-			})
-			aiSetting := setting.AiSetting //获取AI设置
-			q.AnswerAIGet(userCache.UserID, aiSetting.AiUrl, aiSetting.Model, aiSetting.AiType, message, aiSetting.APIKEY)
-		case 2:
-			q.AnswerExternalGet(setting.ApiQueSetting.Url)
-		}
-
+		answer(q, xuexitong.AIProblemMessage(questionAction.Title, q.Type.String(), entity.ExamTurn{
+			XueXFillQue: *q,
+		}))
 	}
 	//简答题
 	for i := range questionAction.Short {
 		q := &questionAction.Short[i] // 获取对应选项
-		switch user.CoursesCustom.AutoExam {
-		case 1:
-			message := xuexitong.AIProblemMessage(questionAction.Title, q.Type.String(), entity.ExamTurn{
-				XueXShortQue: *q,
-			})
-			aiSetting := setting.AiSetting //获取AI设置
-			q.AnswerAIGet(userCache.UserID, aiSetting.AiUrl, aiSetting.Model, aiSetting.AiType, message, aiSetting.APIKEY)
-		case 2:
-			q.AnswerExternalGet(setting.ApiQueSetting.Url)
-		}
-
+		answer(q, xuexitong.AIProblemMessage(questionAction.Title, q.Type.String(), entity.ExamTurn{
+			XueXShortQue: *q,
+		}))
 	}
 	//名词解释
 	for i := range questionAction.TermExplanation {
 		q := &questionAction.TermExplanation[i] // 获取对应选项
-		switch user.CoursesCustom.AutoExam {
-		case 1:
-			message := xuexitong.AIProblemMessage(questionAction.Title, q.Type.String(), entity.ExamTurn{
-				XueXTermExplanationQue: *q,
-			})
-			aiSetting := setting.AiSetting //获取AI设置
-			q.AnswerAIGet(userCache.UserID, aiSetting.AiUrl, aiSetting.Model, aiSetting.AiType, message, aiSetting.APIKEY)
-		case 2:
-			q.AnswerExternalGet(setting.ApiQueSetting.Url)
-		}
-
+		answer(q, xuexitong.AIProblemMessage(questionAction.Title, q.Type.String(), entity.ExamTurn{
+			XueXTermExplanationQue: *q,
+		}))
 	}
 	//论述题
 	for i := range questionAction.Essay {
 		q := &questionAction.Essay[i] // 获取对应选项
-		switch user.CoursesCustom.AutoExam {
-		case 1:
-			message := xuexitong.AIProblemMessage(questionAction.Title, q.Type.String(), entity.ExamTurn{
-				XueXEssayQue: *q,
-			})
-			aiSetting := setting.AiSetting //获取AI设置
-			q.AnswerAIGet(userCache.UserID, aiSetting.AiUrl, aiSetting.Model, aiSetting.AiType, message, aiSetting.APIKEY)
-		case 2:
-			q.AnswerExternalGet(setting.ApiQueSetting.Url)
-		}
-
+		answer(q, xuexitong.AIProblemMessage(questionAction.Title, q.Type.String(), entity.ExamTurn{
+			XueXEssayQue: *q,
+		}))
+	}
+	if paused != nil { //不保存也不提交，留到下次运行
+		lg.Print(lg.INFO, "[", lg.Green, userCache.Name, lg.Default, "] ", "【", courseItem.CourseName, "】", "【", knowledgeItem.Label, " ", knowledgeItem.Name, "】", "【", questionAction.Title, "】", lg.BoldRed, paused.Error(), "，跳过该作业")
+		return
 	}
+	normalizeQuestion(&questionAction) //统一清理各来源的答案格式
 
+	//审核模式或各AI答案存在分歧时只生成待审核文件，人工确认答案后使用review apply提交
+	if user.CoursesCustom.ExamAutoSubmit == 3 || disputed {
+		AnswerFixedPattern(questionAction.Choice, questionAction.Judge, questionAction.Fill, questionAction.Short)
+		saveReview(userCache, user, courseItem, knowledgeItem, questionAction)
+		return
+	}
+	scores := fixedPatternScores(&questionAction)
 	var resultStr string
 	if user.CoursesCustom.ExamAutoSubmit == 0 {
 		AnswerFixedPattern(questionAction.Choice, questionAction.Judge, questionAction.Fill, questionAction.Short)
@@ -887,7 +887,8 @@ func WorkAction(userCache *xuexitongApi.XueXiTUserCache, user *config.Users, set
 		resultStr = xuexitong.WorkNewSubmitAnswerAction(userCache, questionAction, true)
 	} else if user.CoursesCustom.ExamAutoSubmit == 2 {
 		AnswerFixedPattern(questionAction.Choice, questionAction.Judge, questionAction.Fill, questionAction.Short)
-		if CheckAnswerIsAvoid(questionAction.Choice, questionAction.Judge, questionAction.Fill, questionAction.Short) {
+		if confident, total, ok := smartSubmitCheck(&questionAction, scores, user.CoursesCustom.SmartSubmit); !ok {
+			lg.Print(lg.INFO, "[", lg.Green, userCache.Name, lg.Default, "] ", "【", courseItem.CourseName, "】", "【", knowledgeItem.Label, " ", knowledgeItem.Name, "】", "【", questionAction.Title, "】", lg.Yellow, "有把握的题目", strconv.Itoa(confident), "/", strconv.Itoa(total), "未达到智能提交要求，只保存答案")
 			resultStr = xuexitong.WorkNewSubmitAnswerAction(userCache, questionAction, false) //留空了，只保存
 			//如果提交失败那么直接输出AI答题的文本
 			if gojsonq.New().JSONString(resultStr).Find("status") == false {
@@ -910,6 +911,13 @@ func WorkAction(userCache *xuexitongApi.XueXiTUserCache, user *config.Users, set
 			}
 		}
 	}
+	title := knowledgeItem.Label + " " + knowledgeItem.Name + " " + questionAction.Title
+	//从批改结果中学习正确答案，只保存未交卷时页面上没有正确答案，不会学到内容
+	correct := learnQuestion(userCache, title, &questionAction, resultStr)
+	auditQuestion(user, courseItem.CourseName, title, &questionAction, sources, scores, correct)
+	if bankHits > 0 {
+		lg.Print(lg.INFO, "[", lg.Green, userCache.Name, lg.Default, "] ", "【", courseItem.CourseName, "】", "【", knowledgeItem.Label, " ", knowledgeItem.Name, "】", "【", questionAction.Title, "】", lg.Green, "本地题库命中 ", strconv.Itoa(bankHits), " 题")
+	}
 	//提交试卷成功的话{"msg":"success!","stuStatus":4,"backUrl":"","url":"/mooc-ans/api/work?courseid=250215285&workId=b63d4e7466624ace9c382cd112c9c95a&clazzId=125521307&knowledgeid=951783044&ut=s&type=&submit=true&jobid=work-6967802218b44f4dace8e3a8755cf3d9&enc=db5c2413ac1367c5ed28b4cfa5194318&ktoken=c0bf3b45e0b3e625e377cae3b77e1cfa&mooc2=0&skipHeader=true&originJobId=null","status":true}
 	//提交作业失败的话{"msg" : "作业提交失败！","status" : false}
 	if user.CoursesCustom.AutoExam == 1 {
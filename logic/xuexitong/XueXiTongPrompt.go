@@ -0,0 +1,28 @@
+package xuexitong
+
+import (
+	"yatori-go-console/config"
+	"yatori-go-console/utils/prompt"
+
+	"github.com/yatori-dev/yatori-go-core/api/entity"
+	xuexitongApi "github.com/yatori-dev/yatori-go-core/api/xuexitong"
+	"github.com/yatori-dev/yatori-go-core/que-core/aiq"
+	lg "github.com/yatori-dev/yatori-go-core/utils/log"
+)
+
+// customPrompt 按aiSetting.prompts中的模板改写题目描述，kind为提示词种类，没有配置或模板有误时使用内置提示词
+func customPrompt(userCache *xuexitongApi.XueXiTUserCache, user *config.Users, aiSetting config.AiSetting, kind string, data prompt.Data, message aiq.AIChatMessages) aiq.AIChatMessages {
+	data.Platform, data.Account = user.AccountType, user.Account
+	res, err := prompt.Apply(message, aiSetting.Prompt(user.AccountType, kind), data)
+	if err != nil {
+		lg.Print(lg.INFO, "[", lg.Green, userCache.Name, lg.Default, "] ", lg.BoldRed, "提示词模板有误，使用内置提示词：", err.Error())
+	}
+	return res
+}
+
+// questionPrompt 作业题目的提示词
+func questionPrompt(userCache *xuexitongApi.XueXiTUserCache, user *config.Users, aiSetting config.AiSetting, courseName, title string, q entity.AnswerSetter, message aiq.AIChatMessages) aiq.AIChatMessages {
+	info := questionInfo(q)
+	data := prompt.Data{Course: courseName, Title: title, Type: info.qType, Content: info.text, Options: sortedOptions(info.options)}
+	return customPrompt(userCache, user, aiSetting, prompt.Kind(info.qType), data, message)
+}
@@ -0,0 +1,24 @@
+package xuexitong
+
+import (
+	"yatori-go-console/utils/result"
+	"yatori-go-console/utils/retry"
+)
+
+// 学习通错误信息的分类规则
+var resultRules = []result.Rule{
+	{Kind: result.SessionExpired, Keywords: []string{"请重新登录", "登录失效"}},
+	{Kind: result.RateLimited, Keywords: []string{"status code: 429", "频繁"}},
+	{Kind: result.ServerError, Keywords: []string{"status code: 500", "status code: 502", "status code: 503", "status code: 504"}},
+	{Kind: result.Detected, Keywords: []string{"status code: 403", "人脸"}},
+	{Kind: result.NotStarted, Keywords: []string{"课程章节为空", "未开课", "还未开始"}},
+}
+
+func init() {
+	retry.RegisterClassifier("XUEXITONG", result.Classifier(resultRules))
+}
+
+// classifyError 将学习通接口返回的错误转为分类错误
+func classifyError(err error) error {
+	return result.Classify(err, resultRules)
+}
@@ -0,0 +1,34 @@
+package xuexitong
+
+import (
+	"errors"
+	"testing"
+	"yatori-go-console/utils/result"
+	"yatori-go-console/utils/retry"
+)
+
+func TestClassifyError(t *testing.T) {
+	cases := []struct {
+		msg   string
+		kind  result.Kind
+		class retry.Class
+	}{
+		{"unexpected status code: 500", result.ServerError, retry.Retryable},
+		{"status code: 503", result.ServerError, retry.Retryable},
+		{"status code: 403", result.Detected, retry.Permanent},
+		{"需要人脸识别", result.Detected, retry.Permanent},
+		{"status code: 429", result.RateLimited, retry.Retryable},
+		{"登录失效", result.SessionExpired, retry.Retryable},
+		{"课程章节为空", result.NotStarted, retry.Permanent},
+		{"connection reset", result.Unknown, retry.Retryable},
+	}
+	for _, c := range cases {
+		err := classifyError(errors.New(c.msg))
+		if got := result.KindOf(err); got != c.kind {
+			t.Errorf("%q 分类为%v, want %v", c.msg, got, c.kind)
+		}
+		if got := retry.Get("XUEXITONG").Classify(errors.New(c.msg)); got != c.class {
+			t.Errorf("%q 重试类别为%v, want %v", c.msg, got, c.class)
+		}
+	}
+}
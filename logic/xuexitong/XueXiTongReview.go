@@ -0,0 +1,104 @@
+package xuexitong
+
+import (
+	"encoding/json"
+	"strings"
+	"yatori-go-console/config"
+	"yatori-go-console/utils/review"
+
+	"github.com/thedevsaddam/gojsonq"
+	"github.com/yatori-dev/yatori-go-core/aggregation/xuexitong"
+	"github.com/yatori-dev/yatori-go-core/aggregation/xuexitong/point"
+	"github.com/yatori-dev/yatori-go-core/api/entity"
+	xuexitongApi "github.com/yatori-dev/yatori-go-core/api/xuexitong"
+	lg "github.com/yatori-dev/yatori-go-core/utils/log"
+)
+
+// saveReview 将作答完毕的章节作业写入待审核文件
+func saveReview(userCache *xuexitongApi.XueXiTUserCache, user *config.Users, courseItem *xuexitong.XueXiTCourse, knowledgeItem xuexitong.KnowledgeItem, questionAction entity.Question) {
+	item := review.Item{Account: user.Account, Platform: user.AccountType, Kind: "work", Course: courseItem.CourseName, Title: knowledgeItem.Label + " " + knowledgeItem.Name + " " + questionAction.Title}
+	path, err := review.Save(item, "work_"+questionAction.WorkId, questionAction)
+	if err != nil {
+		lg.Print(lg.INFO, "[", lg.Green, userCache.Name, lg.Default, "] ", "【", courseItem.CourseName, "】", "【", questionAction.Title, "】", lg.BoldRed, "写入待审核文件失败：", err.Error())
+		return
+	}
+	lg.Print(lg.INFO, "[", lg.Green, userCache.Name, lg.Default, "] ", "【", courseItem.CourseName, "】", "【", knowledgeItem.Label, " ", knowledgeItem.Name, "】", "【", questionAction.Title, "】", lg.Yellow, "答案已写入待审核文件 ", path, "，人工确认后使用 review apply 提交")
+}
+
+// bbsDraft 待审核的讨论回复，提交时按任务点信息重新拉取讨论
+type bbsDraft struct {
+	Dto     entity.PointBBsDto `json:"dto"`
+	Title   string             `json:"title"`   //讨论标题
+	Content string             `json:"content"` //讨论内容
+	Reply   string             `json:"reply"`   //待发布的回复，可人工修改
+}
+
+// saveBBSReview 将生成的讨论回复写入待审核文件
+func saveBBSReview(userCache *xuexitongApi.XueXiTUserCache, user *config.Users, courseItem *xuexitong.XueXiTCourse, knowledgeItem xuexitong.KnowledgeItem, bbsTopic *point.BBsTopic, bbsDto *entity.PointBBsDto, reply string) {
+	item := review.Item{Account: user.Account, Platform: user.AccountType, Kind: "bbs", Course: courseItem.CourseName, Title: knowledgeItem.Label + " " + knowledgeItem.Name + " " + bbsTopic.Title}
+	path, err := review.Save(item, "bbs_"+bbsTopic.Uuid, bbsDraft{Dto: *bbsDto, Title: bbsTopic.Title, Content: bbsTopic.Content, Reply: reply})
+	if err != nil {
+		lg.Print(lg.INFO, "[", lg.Green, userCache.Name, lg.Default, "] ", "【", courseItem.CourseName, "】", "【", bbsTopic.Title, "】", lg.BoldRed, "写入待审核文件失败：", err.Error())
+		return
+	}
+	lg.Print(lg.INFO, "[", lg.Green, userCache.Name, lg.Default, "] ", "【", courseItem.CourseName, "】", "【", knowledgeItem.Label, " ", knowledgeItem.Name, "】", "【", bbsTopic.Title, "】", lg.Yellow, "讨论回复已写入待审核文件 ", path, "，人工确认后使用 review apply 发布")
+}
+
+// applyBBSReview 发布人工审核后的讨论回复
+func applyBBSReview(cache *xuexitongApi.XueXiTUserCache, user config.Users, item review.Item) {
+	var draft bbsDraft
+	if err := json.Unmarshal(item.Payload, &draft); err != nil {
+		lg.Print(lg.INFO, "[", lg.Green, user.Account, lg.Default, "] ", "【", item.Title, "】 ", lg.BoldRed, "待审核文件格式错误：", err.Error())
+		return
+	}
+	if strings.TrimSpace(draft.Reply) == "" {
+		lg.Print(lg.INFO, "[", lg.Green, user.Account, lg.Default, "] ", "【", item.Title, "】 ", lg.BoldRed, "讨论回复为空，未发布")
+		return
+	}
+	bbsTopic, err := point.PullBbsInfoAction(cache, &draft.Dto) //重新拉取讨论，获取最新的提交凭证
+	if err != nil {
+		lg.Print(lg.INFO, "[", lg.Green, user.Account, lg.Default, "] ", "【", item.Title, "】 ", lg.BoldRed, "拉取讨论失败，未发布：", err.Error())
+		return
+	}
+	report, err := postBBS(cache, bbsTopic, &draft.Dto, draft.Reply)
+	if err != nil {
+		lg.Print(lg.INFO, "[", lg.Green, user.Account, lg.Default, "] ", "【", item.Title, "】 ", lg.BoldRed, "讨论回复发布失败，返回信息：", err.Error())
+		return
+	}
+	review.MarkApplied(item, report)
+	lg.Print(lg.INFO, "[", lg.Green, user.Account, lg.Default, "] ", "【", item.Course, "】", "【", item.Title, "】 ", lg.Green, "审核后的讨论回复已发布，服务器返回信息：", report)
+}
+
+// ApplyReview 登录账号并提交人工审核后的答卷
+func ApplyReview(user config.Users, items []review.Item) {
+	cache := &xuexitongApi.XueXiTUserCache{Name: user.Account, Password: user.Password}
+	if err := xuexitong.XueXiTLoginAction(cache); err != nil {
+		lg.Print(lg.INFO, "[", lg.Green, user.Account, lg.Default, "] ", lg.BoldRed, "登录失败，待审核答卷未提交：", err.Error())
+		return
+	}
+	for _, item := range items {
+		if item.Kind == "bbs" {
+			applyBBSReview(cache, user, item)
+			continue
+		}
+		var questionAction entity.Question
+		if err := json.Unmarshal(item.Payload, &questionAction); err != nil {
+			lg.Print(lg.INFO, "[", lg.Green, user.Account, lg.Default, "] ", "【", item.Title, "】 ", lg.BoldRed, "待审核文件格式错误：", err.Error())
+			continue
+		}
+		normalizeQuestion(&questionAction) //人工修改的答案同样统一格式
+		resultStr := xuexitong.WorkNewSubmitAnswerAction(cache, questionAction, true)
+		if status, _ := gojsonq.New().JSONString(resultStr).Find("status").(bool); !status { //提交失败或被拒绝时保留待审核状态，下次review apply重新提交
+			lg.Print(lg.INFO, "[", lg.Green, user.Account, lg.Default, "] ", "【", item.Course, "】", "【", item.Title, "】 ", lg.BoldRed, "审核答卷提交失败，保留待审核状态，服务器返回信息：", resultStr)
+			continue
+		}
+		sources := map[string]string{}
+		for _, q := range allQuestions(&questionAction) { //答案经过人工确认
+			sources[questionInfo(q).qid] = "review"
+		}
+		correct := learnQuestion(cache, item.Title, &questionAction, resultStr)
+		auditQuestion(&user, item.Course, item.Title, &questionAction, sources, nil, correct)
+		review.MarkApplied(item, resultStr)
+		lg.Print(lg.INFO, "[", lg.Green, user.Account, lg.Default, "] ", "【", item.Course, "】", "【", item.Title, "】 ", lg.Green, "审核答卷已提交，服务器返回信息：", resultStr)
+	}
+}
@@ -0,0 +1,50 @@
+package xuexitong
+
+import (
+	"strings"
+	"yatori-go-console/config"
+
+	"github.com/yatori-dev/yatori-go-core/api/entity"
+)
+
+// confidentAnswer 一道题的答案是否有把握，选择、判断题看修正前答案与选项的相似度，其余题型看每个空是否都有答案
+func confidentAnswer(q entity.AnswerSetter, score float64, scored bool, minSimilarity float64) bool {
+	info := questionInfo(q)
+	if info.answers != nil {
+		return len(*info.answers) > 0 && scored && score >= minSimilarity
+	}
+	if len(info.blanks) == 0 {
+		return false
+	}
+	for _, answer := range blankAnswers(info.blanks) {
+		if strings.TrimSpace(answer) == "" {
+			return false
+		}
+	}
+	return true
+}
+
+// smartSubmitCheck 按智能提交规则判断是否交卷，scores为修正前选择、判断题的相似度，key为题目ID，
+// 返回有把握的题数、参与判定的题数以及是否可以交卷
+func smartSubmitCheck(questionAction *entity.Question, scores map[string]float64, rules config.SmartSubmit) (int, int, bool) {
+	confident, total := 0, 0
+	ok := true
+	for _, q := range allQuestions(questionAction) {
+		info := questionInfo(q)
+		rule := rules.Rule(info.qType)
+		if rule.Ignore == 1 {
+			continue
+		}
+		total++
+		score, scored := scores[info.qid]
+		if confidentAnswer(q, score, scored, rule.MinSimilarity) {
+			confident++
+		} else if rule.Required == 1 {
+			ok = false
+		}
+	}
+	if total > 0 && float64(confident) < rules.Ratio()*float64(total) {
+		ok = false
+	}
+	return confident, total, ok
+}
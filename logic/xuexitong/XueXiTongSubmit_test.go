@@ -0,0 +1,47 @@
+package xuexitong
+
+import (
+	"testing"
+	"yatori-go-console/config"
+
+	"github.com/yatori-dev/yatori-go-core/api/entity"
+	"github.com/yatori-dev/yatori-go-core/que-core/qtype"
+)
+
+// submitPaper 两道单选一道填空一道简答，简答留空
+func submitPaper() *entity.Question {
+	return &entity.Question{
+		Choice: []entity.ChoiceQue{
+			{Type: qtype.SingleChoice, Qid: "c1", Text: "1+1", Options: map[string]string{"A": "2", "B": "3"}, Answers: []string{"A"}},
+			{Type: qtype.SingleChoice, Qid: "c2", Text: "2+2", Options: map[string]string{"A": "4", "B": "5"}, Answers: []string{"A"}},
+		},
+		Fill:  []entity.FillQue{{Type: qtype.FillInTheBlank, Qid: "f1", Text: "填空", OpFromAnswer: map[string][]string{"1": {"答案"}}}},
+		Short: []entity.ShortQue{{Type: qtype.ShortAnswer, Qid: "s1", Text: "简答", OpFromAnswer: map[string][]string{"1": {""}}}},
+	}
+}
+
+func TestSmartSubmitCheck(t *testing.T) {
+	tests := []struct {
+		name      string
+		scores    map[string]float64
+		rules     config.SmartSubmit
+		confident int
+		total     int
+		ok        bool
+	}{
+		{"默认每道题都要有把握", map[string]float64{"c1": 1, "c2": 1}, config.SmartSubmit{}, 3, 4, false},
+		{"忽略简答题", map[string]float64{"c1": 1, "c2": 1}, config.SmartSubmit{Types: map[string]config.SmartSubmitRule{"简答": {Ignore: 1}}}, 3, 3, true},
+		{"相似度不足", map[string]float64{"c1": 1, "c2": 0.5}, config.SmartSubmit{MinRatio: 0.5}, 2, 4, true},
+		{"没有相似度视为没把握", map[string]float64{"c1": 1}, config.SmartSubmit{MinRatio: 0.75}, 2, 4, false},
+		{"题型单独的相似度", map[string]float64{"c1": 1, "c2": 0.5}, config.SmartSubmit{MinRatio: 0.5, Types: map[string]config.SmartSubmitRule{"单选题": {MinSimilarity: 0.4}}}, 3, 4, true},
+		{"必须题型没把握不交卷", map[string]float64{"c1": 1, "c2": 1}, config.SmartSubmit{MinRatio: 0.5, Types: map[string]config.SmartSubmitRule{"简答": {Required: 1}}}, 3, 4, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			confident, total, ok := smartSubmitCheck(submitPaper(), tt.scores, tt.rules)
+			if confident != tt.confident || total != tt.total || ok != tt.ok {
+				t.Fatalf("smartSubmitCheck = %d, %d, %v, want %d, %d, %v", confident, total, ok, tt.confident, tt.total, tt.ok)
+			}
+		})
+	}
+}
@@ -0,0 +1,31 @@
+package yinghua
+
+import (
+	"yatori-go-console/config"
+	"yatori-go-console/utils/audit"
+	"yatori-go-console/utils/qbank"
+
+	lg "github.com/yatori-dev/yatori-go-core/utils/log"
+)
+
+// auditPaper 将提交的答卷逐题写入答题记录，correct为批改结果，key为题目序号
+func auditPaper(user *config.Users, courseName string, paper workPaper, correct map[string]bool) {
+	var records []audit.Record
+	for i, topic := range paper.Topics {
+		record := audit.Record{Account: user.Account, Platform: user.AccountType, Course: courseName, Title: paper.Title, Kind: paper.Kind,
+			Type: topic.Type, Content: topic.Content, Options: topic.Options, Answers: topic.Answers}
+		if i < len(paper.Sources) {
+			record.Source = paper.Sources[i]
+		}
+		if len(topic.Options) > 0 {
+			record.Similarity = qbank.OptionScore(topic.Answers, topic.Options)
+		}
+		if ok, graded := correct[topic.Index]; graded {
+			record.Correct = &ok
+		}
+		records = append(records, record)
+	}
+	if err := audit.Append(records); err != nil {
+		lg.Print(lg.INFO, "[", lg.Green, user.Account, lg.Default, "] ", "【", paper.Title, "】 ", lg.BoldRed, "写入答题记录失败：", err.Error())
+	}
+}
@@ -0,0 +1,53 @@
+package yinghua
+
+import (
+	"strconv"
+	"yatori-go-console/utils/graded"
+	"yatori-go-console/utils/qbank"
+
+	yinghuaApi "github.com/yatori-dev/yatori-go-core/api/yinghua"
+	lg "github.com/yatori-dev/yatori-go-core/utils/log"
+)
+
+// learnPaper 交卷后拉取批改后的答卷，把平台公布的正确答案以verified来源写入本地题库，
+// 返回每道题是否答对，key为题目序号，平台没有公布答案时返回空
+func learnPaper(userCache *yinghuaApi.YingHuaUserCache, paper workPaper) map[string]bool {
+	detail := yinghuaApi.WorkedFinallyDetailApi
+	if paper.Kind == "exam" {
+		detail = yinghuaApi.ExamFinallyDetailApi
+	}
+	html, err := detail(*userCache, paper.CourseId, paper.NodeId, paper.Id, 3, nil)
+	if err != nil {
+		return nil
+	}
+	items := graded.Parse(html)
+	if len(items) == 0 {
+		return nil
+	}
+	correct := map[string]bool{}
+	learned := 0
+	matcher := graded.NewMatcher(items)
+	for _, topic := range paper.Topics {
+		var options []string
+		if topic.Type == "单选" || topic.Type == "多选" { //选择题的选项也要对应上，判断题的选项在批改页面中不一定显示
+			options = topic.Options
+		}
+		item, ok, unique := matcher.Find(topic.Content, options)
+		if !ok || !unique { //对应不唯一时不作为正确答案写入题库
+			continue
+		}
+		answers := item.Answers
+		if topic.Type == "简答" { //简答题答案不拆分，也无法判断是否答对
+			answers = []string{item.Raw}
+		} else {
+			answers = graded.Resolve(answers, topic.Options, topic.Type == "判断")
+			correct[topic.Index] = graded.Same(topic.Answers, answers)
+		}
+		qbank.Store(qbank.Entry{Type: topic.Type, Content: topic.Content, Options: topic.Options, Answers: answers, Source: qbank.SourceVerified})
+		learned++
+	}
+	if learned > 0 {
+		lg.Print(lg.INFO, `[`, userCache.Account, `] `, lg.Green, "【", paper.Title, "】已从批改结果中学习", strconv.Itoa(learned), "道题的正确答案")
+	}
+	return correct
+}
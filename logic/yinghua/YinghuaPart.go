@@ -3,20 +3,20 @@ package yinghua
 import (
 	"fmt"
 	"log"
-	"os"
-	"regexp"
 	"strconv"
 	"sync"
 	time2 "time"
 	"yatori-go-console/config"
 	utils2 "yatori-go-console/utils"
+	"yatori-go-console/utils/health"
 	modelLog "yatori-go-console/utils/log"
+	"yatori-go-console/utils/pacing"
+	"yatori-go-console/utils/result"
+	"yatori-go-console/utils/retry"
+	"yatori-go-console/utils/schedule"
 
-	"github.com/thedevsaddam/gojsonq"
 	"github.com/yatori-dev/yatori-go-core/aggregation/yinghua"
 	yinghuaApi "github.com/yatori-dev/yatori-go-core/api/yinghua"
-	"github.com/yatori-dev/yatori-go-core/que-core/aiq"
-	"github.com/yatori-dev/yatori-go-core/que-core/external"
 	lg "github.com/yatori-dev/yatori-go-core/utils/log"
 )
 
@@ -24,6 +24,29 @@ var videosLock sync.WaitGroup //视频节点锁
 var nodesLock sync.WaitGroup  //节点锁
 var usersLock sync.WaitGroup  //用户锁
 
+// queueLocked 将未解锁的课程或节点加入待解锁队列，守护模式下会在解锁后自动回来学习，返回是否加入成功
+func queueLocked(user *config.Users, course, node string, until time2.Time) bool {
+	label := " 【" + course + "】 "
+	if node != "" {
+		label += "【" + node + "】 "
+	}
+	if until.IsZero() {
+		lg.Print(lg.INFO, "[", lg.Green, user.Account, lg.Default, "] ", label, lg.BoldRed, "未到解锁时间但获取不到解锁时间，无法加入待解锁队列，已跳过")
+		return false
+	}
+	if !until.After(time2.Now()) {
+		lg.Print(lg.INFO, "[", lg.Green, user.Account, lg.Default, "] ", label, lg.BoldRed, "解锁时间", until.Format("2006-01-02 15:04:05"), "已过但平台仍未开放，未加入待解锁队列，已跳过")
+		return false
+	}
+	err := schedule.AddPending(schedule.PendingItem{Account: user.Account, Platform: user.AccountType, Course: course, Node: node, Until: until})
+	if err != nil {
+		lg.Print(lg.INFO, "[", lg.Green, user.Account, lg.Default, "] ", label, lg.BoldRed, "加入待解锁队列失败：", err.Error())
+		return false
+	}
+	lg.Print(lg.INFO, "[", lg.Green, user.Account, lg.Default, "] ", label, lg.Yellow, "未到解锁时间，已加入待解锁队列，解锁时间：", until.Format("2006-01-02 15:04:05"))
+	return true
+}
+
 // 用于过滤英华账号
 func FilterAccount(configData *config.JSONDataForConfig) []config.Users {
 	var users []config.Users //用于收集英华账号
@@ -83,7 +106,12 @@ func UserLoginOperation(users []config.Users) []*yinghuaApi.YingHuaUserCache {
 var soundMut sync.Mutex
 
 func userBlock(setting config.Setting, user *config.Users, cache *yinghuaApi.YingHuaUserCache) {
-	list, _ := yinghua.CourseListAction(cache) //拉取课程列表
+	list, err := retry.DoValue(retry.Get(user.AccountType), "["+cache.Account+"] ", func() ([]yinghua.YingHuaCourse, error) {
+		return yinghua.CourseListAction(cache) //拉取课程列表
+	})
+	if err != nil {
+		lg.Print(lg.INFO, "[", lg.Green, cache.Account, lg.Default, "] ", lg.BoldRed, "拉取课程列表失败：", err.Error())
+	}
 	lg.Print(lg.INFO, "[", lg.Green, cache.Account, lg.Default, "] ", lg.Purple, "正在定位上次学习位置...")
 	for _, item := range list { //遍历所有待刷视频
 		nodesLock.Add(1)
@@ -143,31 +171,44 @@ func nodeListStudy(setting config.Setting, user *config.Users, userCache *yinghu
     //如果课程时间未到开课时间则直接return
     //{"_code":9,"status":false,"msg":"课程还未开始!","result":{}}
     if time2.Now().Before(course.StartDate) {
-        modelLog.ModelPrint(setting.BasicSetting.LogModel == 0, lg.INFO, "[", lg.Green, userCache.Account, lg.Default, "] ", " 【", courseDisplayName, "】 >>> ", lg.Red, "该课程还未开始已自动跳过")
+        modelLog.ModelPrint(setting.BasicSetting.LogModel == 0, lg.INFO, "[", lg.Green, userCache.Account, lg.Default, "] ", " 【", courseDisplayName, "】 >>> ", lg.Red, "该课程还未开始，开课时间：", course.StartDate.Format("2006-01-02"))
+        queueLocked(user, course.Name, "", course.StartDate) //加入待解锁队列，结果由queueLocked打印
         nodesLock.Done()
         return
     }
     //执行刷课---------------------------------
-    nodeList, _ := yinghua.VideosListAction(userCache, *course) //拉取对应课程的视频列表
+    nodeList, err := retry.DoValue(retry.Get(user.AccountType), "["+userCache.Account+"] ", func() ([]yinghua.YingHuaNode, error) {
+        return yinghua.VideosListAction(userCache, *course) //拉取对应课程的视频列表
+    })
+    if err != nil {
+        lg.Print(lg.INFO, "[", lg.Green, userCache.Account, lg.Default, "] ", " 【"+courseDisplayName+"】 ", lg.BoldRed, "拉取视频列表失败，该课程已跳过：", err.Error())
+        nodesLock.Done()
+        return
+    }
     // 提交学时
     for _, node := range nodeList {
+        schedule.WaitWindow(userCache.Account) //不在学习时间窗口内则在此暂停
         //视频处理逻辑
-        switch user.CoursesCustom.VideoModel { //根据视频模式进行刷课
+        videoModel := user.CoursesCustom.VideoModel
+        if !user.CoursesCustom.TaskEnabled(config.TaskVideo) {
+            videoModel = 0
+        }
+        switch videoModel { //根据视频模式进行刷课
         case 1:
-            videoAction(setting, user, userCache, node) //普通模式
+            videoAction(setting, user, userCache, course, node) //普通模式
             break
         case 2:
-            videoVioLenceAction(setting, user, userCache, node) //暴力模式
+            videoVioLenceAction(setting, user, userCache, course, node) //暴力模式
             break
         case 3:
-            videoBadRedAction(setting, user, userCache, node) //去红模式
+            videoBadRedAction(setting, user, userCache, course, node) //去红模式
             break
 
         }
         //作业处理逻辑
-        workAction(setting, user, userCache, node)
+        workAction(setting, user, userCache, course, node)
         //考试处理逻辑
-        examAction(setting, user, userCache, node)
+        examAction(setting, user, userCache, course, node)
 
         action, err := yinghua.CourseDetailAction(userCache, course.Id)
         if err != nil {
@@ -181,7 +222,7 @@ func nodeListStudy(setting config.Setting, user *config.Users, userCache *yinghu
 }
 
 // videoAction 刷视频逻辑抽离
-func videoAction(setting config.Setting, user *config.Users, UserCache *yinghuaApi.YingHuaUserCache, node yinghua.YingHuaNode) {
+func videoAction(setting config.Setting, user *config.Users, UserCache *yinghuaApi.YingHuaUserCache, course *yinghua.YingHuaCourse, node yinghua.YingHuaNode) {
 	if !node.TabVideo { //过滤非视频节点
 		return
 	}
@@ -191,12 +232,15 @@ func videoAction(setting config.Setting, user *config.Users, UserCache *yinghuaA
 	modelLog.ModelPrint(setting.BasicSetting.LogModel == 0, lg.INFO, "[", lg.Green, UserCache.Account, lg.Default, "] ", lg.Yellow, "正在学习视频：", lg.Default, " 【"+node.Name+"】 ")
 	time := node.ViewedDuration //设置当前观看时间为最后看视频的时间
 	studyId := "0"              //服务器端分配的学习ID
+	pace := pacing.Get(user.AccountType)
+	bo := retry.NewBackoff(user.AccountType)
 	for {
-		time += 5
+		time += pace.Step
 		if node.Progress == 100 {
 			modelLog.ModelPrint(setting.BasicSetting.LogModel == 0, lg.INFO, "[", lg.Green, UserCache.Account, lg.Default, "] ", " 【", node.Name, "】 ", " ", lg.Blue, "学习完毕")
 			break //如果看完了，也就是进度为100那么直接跳过
 		}
+		schedule.WaitWindow(UserCache.Account) //不在学习时间窗口内则在此暂停
 		//提交学时
 		sub, err := yinghua.SubmitStudyTimeAction(UserCache, node.Id, studyId, time)
 		if err != nil {
@@ -205,32 +249,34 @@ func videoAction(setting config.Setting, user *config.Users, UserCache *yinghuaA
 		//超时重登检测
 		yinghua.LoginTimeoutAfreshAction(UserCache, sub)
 		lg.Print(lg.DEBUG, "---", node.Id, sub)
-		//如果提交学时不成功
-		msgVal := gojsonq.New().JSONString(sub).Find("msg")
-		msg, ok := msgVal.(string)
-		if !ok || msg == "" {
-			lg.Print(lg.INFO, "[", lg.Green, UserCache.Account, lg.Default, "] ", " 【", node.Name, "】 ", lg.Red, "提交状态异常，msg 字段为空或格式错误", sub)
-			time2.Sleep(10 * time2.Second)
-			continue
-		}
-		if msg != "提交学时成功!" {
-			lg.Print(lg.INFO, "[", lg.Green, UserCache.Account, lg.Default, "] ", " 【", node.Name, "】 >>> ", "提交状态：", lg.Red, sub)
-			//{"_code":9,"status":false,"msg":"该课程解锁时间【2024-11-14 12:00:00】未到!","result":{}}，如果未到解锁时间则跳过
-			reg1 := regexp.MustCompile(`该课程解锁时间【[^【]*】未到!`)
-			if reg1.MatchString(msg) {
-				modelLog.ModelPrint(setting.BasicSetting.LogModel == 0, lg.INFO, "[", lg.Green, UserCache.Account, lg.Default, "] ", " 【", node.Name, "】 >>> ", lg.Red, "该课程未到解锁时间已自动跳过")
+		//解析提交结果
+		res, err := decodeSubmit(sub, err)
+		if err != nil {
+			lg.Print(lg.INFO, "[", lg.Green, UserCache.Account, lg.Default, "] ", " 【", node.Name, "】 >>> ", "提交状态：", lg.Red, err.Error())
+			//如果未到解锁时间则加入待解锁队列，守护模式下会在解锁后回来继续学习
+			if result.Is(err, result.Locked) {
+				until, ok := result.LockedUntil(err)
+				if !ok {
+					until = node.UnlockTime
+				}
+				queueLocked(user, course.Name, node.Name, until) //结果由queueLocked打印
+				break
+			}
+			if result.Is(err, result.Closed) { //已经结束的不会再开放，直接跳过
+				modelLog.ModelPrint(setting.BasicSetting.LogModel == 0, lg.INFO, "[", lg.Green, UserCache.Account, lg.Default, "] ", " 【", node.Name, "】 >>> ", lg.Yellow, "该节点已结束，已跳过")
+				break
+			}
+			if !bo.Wait(err) {
+				lg.Print(lg.INFO, "[", lg.Green, UserCache.Account, lg.Default, "] ", " 【", node.Name, "】 >>> ", lg.BoldRed, "多次提交学时失败，该视频已标记为失败并跳过")
 				break
 			}
-			time2.Sleep(10 * time2.Second)
 			continue
 		}
+		bo.Reset()
 		//打印日志部分
-		studyIdVal := gojsonq.New().JSONString(sub).Find("result.data.studyId")
-		if idFloat, ok := studyIdVal.(float64); ok {
-			studyId = strconv.Itoa(int(idFloat))
-		}
-		modelLog.ModelPrint(setting.BasicSetting.LogModel == 0, lg.INFO, "[", lg.Green, UserCache.Account, lg.Default, "] ", " 【", node.Name, "】 >>> ", "提交状态：", lg.Green, msg, lg.Default, " ", "观看时间：", strconv.Itoa(time)+"/"+strconv.Itoa(node.VideoDuration), " ", "观看进度：", fmt.Sprintf("%.2f", float32(time)/float32(node.VideoDuration)*100), "%")
-		time2.Sleep(5 * time2.Second)
+		studyId = strconv.Itoa(res.Result.Data.StudyId)
+		modelLog.ModelPrint(setting.BasicSetting.LogModel == 0, lg.INFO, "[", lg.Green, UserCache.Account, lg.Default, "] ", " 【", node.Name, "】 >>> ", "提交状态：", lg.Green, res.Msg, lg.Default, " ", "观看时间：", strconv.Itoa(time)+"/"+strconv.Itoa(node.VideoDuration), " ", "观看进度：", fmt.Sprintf("%.2f", float32(time)/float32(node.VideoDuration)*100), "%")
+		pace.Wait()
 		if time >= node.VideoDuration {
 			break //如果看完该视频则直接下一个
 		}
@@ -238,7 +284,7 @@ func videoAction(setting config.Setting, user *config.Users, UserCache *yinghuaA
 }
 
 // videoAction 刷视频逻辑抽离(暴力模式)
-func videoVioLenceAction(setting config.Setting, user *config.Users, UserCache *yinghuaApi.YingHuaUserCache, node yinghua.YingHuaNode) {
+func videoVioLenceAction(setting config.Setting, user *config.Users, UserCache *yinghuaApi.YingHuaUserCache, course *yinghua.YingHuaCourse, node yinghua.YingHuaNode) {
 	if !node.TabVideo { //过滤非视频节点
 		return
 	}
@@ -254,12 +300,15 @@ func videoVioLenceAction(setting config.Setting, user *config.Users, UserCache *
 		modelLog.ModelPrint(setting.BasicSetting.LogModel == 0, lg.INFO, "[", lg.Green, UserCache.Account, lg.Default, "] ", lg.Yellow, "正在学习视频：", lg.Default, " 【"+node.Name+"】 ")
 		time := node.ViewedDuration //设置当前观看时间为最后看视频的时间
 		studyId := "0"              //服务器端分配的学习ID
+		pace := pacing.Get(user.AccountType)
+		bo := retry.NewBackoff(user.AccountType)
 		for {
-			time += 5
+			time += pace.Step
 			if node.Progress == 100 {
 				modelLog.ModelPrint(setting.BasicSetting.LogModel == 0, lg.INFO, "[", lg.Green, UserCache.Account, lg.Default, "] ", " 【", node.Name, "】 ", " ", lg.Blue, "学习完毕")
 				break //如果看完了，也就是进度为100那么直接跳过
 			}
+			schedule.WaitWindow(UserCache.Account) //不在学习时间窗口内则在此暂停
 			//提交学时
 			sub, err := yinghua.SubmitStudyTimeAction(UserCache, node.Id, studyId, time)
 			if err != nil {
@@ -268,31 +317,35 @@ func videoVioLenceAction(setting config.Setting, user *config.Users, UserCache *
 			//超时重登检测
 			yinghua.LoginTimeoutAfreshAction(UserCache, sub)
 			lg.Print(lg.DEBUG, "---", node.Id, sub)
-			//如果提交学时不成功
-			msgVal := gojsonq.New().JSONString(sub).Find("msg")
-			msg, ok := msgVal.(string)
-			if !ok || msg == "" {
-				lg.Print(lg.INFO, "[", lg.Green, UserCache.Account, lg.Default, "] ", lg.Red, "提交状态异常，msg 字段为空或格式错误")
-				time2.Sleep(10 * time2.Second)
-				continue
-			}
-
-			if msg != "提交学时成功!" {
-				lg.Print(lg.INFO, "[", lg.Green, UserCache.Account, lg.Default, "] ", " 【", node.Name, "】 >>> ", "提交状态：", lg.Red, sub)
-
-				reg1 := regexp.MustCompile(`该课程解锁时间【[^【]*】未到!`)
-				if reg1.MatchString(msg) {
-					modelLog.ModelPrint(setting.BasicSetting.LogModel == 0, lg.INFO, "[", lg.Green, UserCache.Account, lg.Default, "] ", " 【", node.Name, "】 >>> ", lg.Red, "该课程未到解锁时间已自动跳过")
+			//解析提交结果
+			res, err := decodeSubmit(sub, err)
+			if err != nil {
+				lg.Print(lg.INFO, "[", lg.Green, UserCache.Account, lg.Default, "] ", " 【", node.Name, "】 >>> ", "提交状态：", lg.Red, err.Error())
+				//如果未到解锁时间则加入待解锁队列，守护模式下会在解锁后回来继续学习
+				if result.Is(err, result.Locked) {
+					until, ok := result.LockedUntil(err)
+					if !ok {
+						until = node.UnlockTime
+					}
+					queueLocked(user, course.Name, node.Name, until) //结果由queueLocked打印
+					break
+				}
+				if result.Is(err, result.Closed) { //已经结束的不会再开放，直接跳过
+					modelLog.ModelPrint(setting.BasicSetting.LogModel == 0, lg.INFO, "[", lg.Green, UserCache.Account, lg.Default, "] ", " 【", node.Name, "】 >>> ", lg.Yellow, "该节点已结束，已跳过")
+					break
+				}
+				if !bo.Wait(err) {
+					lg.Print(lg.INFO, "[", lg.Green, UserCache.Account, lg.Default, "] ", " 【", node.Name, "】 >>> ", lg.BoldRed, "多次提交学时失败，该视频已标记为失败并跳过")
 					break
 				}
-				time2.Sleep(10 * time2.Second)
 				continue
 			}
+			bo.Reset()
 			//打印日志部分
-			studyId = strconv.Itoa(int(gojsonq.New().JSONString(sub).Find("result.data.studyId").(float64)))
-			modelLog.ModelPrint(setting.BasicSetting.LogModel == 0, lg.INFO, "[", lg.Green, UserCache.Account, lg.Default, "] ", " 【", node.Name, "】 >>> ", "提交状态：", lg.Green, msg, lg.Default, " ", "观看时间：", strconv.Itoa(time)+"/"+strconv.Itoa(node.VideoDuration), " ", "观看进度：", fmt.Sprintf("%.2f", float32(time)/float32(node.VideoDuration)*100), "%")
+			studyId = strconv.Itoa(res.Result.Data.StudyId)
+			modelLog.ModelPrint(setting.BasicSetting.LogModel == 0, lg.INFO, "[", lg.Green, UserCache.Account, lg.Default, "] ", " 【", node.Name, "】 >>> ", "提交状态：", lg.Green, res.Msg, lg.Default, " ", "观看时间：", strconv.Itoa(time)+"/"+strconv.Itoa(node.VideoDuration), " ", "观看进度：", fmt.Sprintf("%.2f", float32(time)/float32(node.VideoDuration)*100), "%")
 
-			time2.Sleep(5 * time2.Second)
+			pace.Wait()
 			if time >= node.VideoDuration {
 				break //如果看完该视频则直接下一个
 			}
@@ -302,7 +355,7 @@ func videoVioLenceAction(setting config.Setting, user *config.Users, UserCache *
 }
 
 // videoBadRedAction 去红模式
-func videoBadRedAction(setting config.Setting, user *config.Users, UserCache *yinghuaApi.YingHuaUserCache, node yinghua.YingHuaNode) {
+func videoBadRedAction(setting config.Setting, user *config.Users, UserCache *yinghuaApi.YingHuaUserCache, course *yinghua.YingHuaCourse, node yinghua.YingHuaNode) {
 	if !node.TabVideo { //过滤非视频节点
 		return
 	}
@@ -314,7 +367,9 @@ func videoBadRedAction(setting config.Setting, user *config.Users, UserCache *yi
 	time := node.ViewedDuration //设置当前观看时间为最后看视频的时间
 
 	studyId := "0" //服务器端分配的学习ID
+	bo := retry.NewBackoff(user.AccountType)
 	for {
+		schedule.WaitWindow(UserCache.Account) //不在学习时间窗口内则在此暂停
 		//提交学时
 		sub, err := yinghua.SubmitStudyTimeAction(UserCache, node.Id, studyId, time)
 		if err != nil {
@@ -323,159 +378,182 @@ func videoBadRedAction(setting config.Setting, user *config.Users, UserCache *yi
 		//超时重登检测
 		yinghua.LoginTimeoutAfreshAction(UserCache, sub)
 		lg.Print(lg.DEBUG, "---", node.Id, sub)
-		//如果提交学时不成功
-		msgVal := gojsonq.New().JSONString(sub).Find("msg")
-		msg, ok := msgVal.(string)
-		if !ok || msg == "" {
-			lg.Print(lg.INFO, "[", lg.Green, UserCache.Account, lg.Default, "] ", " 【", node.Name, "】 ", lg.Red, "提交状态异常，msg 字段为空或格式错误", sub)
-			time2.Sleep(10 * time2.Second)
-			continue
-		}
-		if msg != "提交学时成功!" {
-			lg.Print(lg.INFO, "[", lg.Green, UserCache.Account, lg.Default, "] ", " 【", node.Name, "】 >>> ", "提交状态：", lg.Red, sub)
-			//{"_code":9,"status":false,"msg":"该课程解锁时间【2024-11-14 12:00:00】未到!","result":{}}，如果未到解锁时间则跳过
-			reg1 := regexp.MustCompile(`该课程解锁时间【[^【]*】未到!`)
-			if reg1.MatchString(msg) {
-				modelLog.ModelPrint(setting.BasicSetting.LogModel == 0, lg.INFO, "[", lg.Green, UserCache.Account, lg.Default, "] ", " 【", node.Name, "】 >>> ", lg.Red, "该课程未到解锁时间已自动跳过")
+		//解析提交结果
+		res, err := decodeSubmit(sub, err)
+		if err != nil {
+			lg.Print(lg.INFO, "[", lg.Green, UserCache.Account, lg.Default, "] ", " 【", node.Name, "】 >>> ", "提交状态：", lg.Red, err.Error())
+			//如果未到解锁时间则加入待解锁队列，守护模式下会在解锁后回来继续学习
+			if result.Is(err, result.Locked) {
+				until, ok := result.LockedUntil(err)
+				if !ok {
+					until = node.UnlockTime
+				}
+				queueLocked(user, course.Name, node.Name, until) //结果由queueLocked打印
+				break
+			}
+			if result.Is(err, result.Closed) { //已经结束的不会再开放，直接跳过
+				modelLog.ModelPrint(setting.BasicSetting.LogModel == 0, lg.INFO, "[", lg.Green, UserCache.Account, lg.Default, "] ", " 【", node.Name, "】 >>> ", lg.Yellow, "该节点已结束，已跳过")
+				break
+			}
+			if !bo.Wait(err) {
+				lg.Print(lg.INFO, "[", lg.Green, UserCache.Account, lg.Default, "] ", " 【", node.Name, "】 >>> ", lg.BoldRed, "多次提交学时失败，该视频已标记为失败并跳过")
 				break
 			}
-			time2.Sleep(10 * time2.Second)
 			continue
 		}
+		bo.Reset()
 		//打印日志部分
-		studyIdVal := gojsonq.New().JSONString(sub).Find("result.data.studyId")
-		if idFloat, ok := studyIdVal.(float64); ok {
-			studyId = strconv.Itoa(int(idFloat))
-		}
-		modelLog.ModelPrint(setting.BasicSetting.LogModel == 0, lg.INFO, "[", lg.Green, UserCache.Account, lg.Default, "] ", " 【", node.Name, "】 >>> ", lg.Red, " 去红模式 ", lg.Default, "提交状态：", lg.Green, msg, lg.Default, " ", "观看时间：", strconv.Itoa(time)+"/"+strconv.Itoa(node.VideoDuration), " ", "观看进度：", fmt.Sprintf("%.2f", float32(time)/float32(node.VideoDuration)*100), "%")
-		time2.Sleep(8 * time2.Second) //隔8s下一个去红
-		break                         //因为是去红模式，所以直接退出
+		studyId = strconv.Itoa(res.Result.Data.StudyId)
+		modelLog.ModelPrint(setting.BasicSetting.LogModel == 0, lg.INFO, "[", lg.Green, UserCache.Account, lg.Default, "] ", " 【", node.Name, "】 >>> ", lg.Red, " 去红模式 ", lg.Default, "提交状态：", lg.Green, res.Msg, lg.Default, " ", "观看时间：", strconv.Itoa(time)+"/"+strconv.Itoa(node.VideoDuration), " ", "观看进度：", fmt.Sprintf("%.2f", float32(time)/float32(node.VideoDuration)*100), "%")
+		pacing.Get(user.AccountType).WaitGap() //隔一个任务点间隔再去红下一个
+		break                                  //因为是去红模式，所以直接退出
 	}
 }
 
 // workAction 作业处理逻辑
-func workAction(setting config.Setting, user *config.Users, userCache *yinghuaApi.YingHuaUserCache, node yinghua.YingHuaNode) {
-	if user.CoursesCustom.AutoExam == 0 { //是否打开了自动考试开关
+func workAction(setting config.Setting, user *config.Users, userCache *yinghuaApi.YingHuaUserCache, course *yinghua.YingHuaCourse, node yinghua.YingHuaNode) {
+	if !user.CoursesCustom.TaskEnabled(config.TaskWork) { //是否打开了自动考试开关和对应任务开关
 		return
 	}
 	if !node.TabWork { //过滤非作业节点
 		return
 	}
-	if user.CoursesCustom.AutoExam == 1 {
-		//检测AI可用性
-		err := aiq.AICheck(setting.AiSetting.AiUrl, setting.AiSetting.Model, setting.AiSetting.APIKEY, setting.AiSetting.AiType)
-		if err != nil {
-			lg.Print(lg.INFO, lg.BoldRed, "<"+setting.AiSetting.AiType+">", "AI不可用，错误信息："+err.Error())
-			os.Exit(0)
-		}
-	}
-
-	if user.CoursesCustom.AutoExam == 2 {
-		err := external.CheckApiQueRequest(setting.ApiQueSetting.Url, 3, nil)
-		if err != nil {
-			lg.Print(lg.INFO, lg.BoldRed, "外置题库不可用，错误信息："+err.Error())
-			os.Exit(0)
-		}
+	//答题依赖已在启动时检测，不可用时跳过答题继续学习
+	if ok, err := health.Ready(user.CoursesCustom.AnswerSources()); !ok {
+		lg.Print(lg.INFO, "[", lg.Green, userCache.Account, lg.Default, "] ", " 【", node.Name, "】 ", lg.BoldRed, "答案来源不可用，跳过该章节作业：", err.Error())
+		return
 	}
 	//获取作业详细信息
-	detailAction, _ := yinghua.WorkDetailAction(userCache, node.Id)
+	detailAction, _ := retry.DoValue(retry.Get(user.AccountType), "["+userCache.Account+"] ", func() ([]yinghua.YingHuaWork, error) {
+		return yinghua.WorkDetailAction(userCache, node.Id)
+	})
 	////{"_code":9,"status":false,"msg":"考试测试时间还未开始","result":{}}
 	if len(detailAction) == 0 { //过滤没有作业内容的
 		return
 	}
 	modelLog.ModelPrint(setting.BasicSetting.LogModel == 0, lg.INFO, "[", lg.Green, userCache.Account, lg.Default, "] ", "<"+setting.AiSetting.AiType+">", lg.Default, " 【"+node.Name+"】 ", lg.Yellow, "正在AI自动写章节作业...")
 	//开始写作业
+	hints := retakeHints{}
+	answer := newAnswerer(setting, user, userCache, course.Name, hints)
+	targetScore := user.CoursesCustom.GetTargetScore(course.Name)
 	for _, work := range detailAction {
-		var err error
-		switch user.CoursesCustom.AutoExam {
-		case 1:
-			err = yinghua.StartWorkAction(userCache, work, setting.AiSetting.AiUrl, setting.AiSetting.Model, setting.AiSetting.APIKEY, setting.AiSetting.AiType, user.CoursesCustom.ExamAutoSubmit)
-			break
-		case 2:
-			err = yinghua.StartWorkForExternalAction(userCache, setting.ApiQueSetting.Url, work, user.CoursesCustom.ExamAutoSubmit)
-			break
-		}
-
-		if err != nil {
-			lg.Print(lg.INFO, "[", lg.Green, userCache.Account, lg.Default, "] ", "<"+setting.AiSetting.AiType+">", " 【", node.Name, "】 ", lg.BoldRed, "该章节作业无法正常执行，服务器返回信息：", err.Error())
+		if reviewPending(user, node, "work", work.WorkId, work.Title) {
 			continue
 		}
-		if user.CoursesCustom.ExamAutoSubmit == 1 {
+		//得分低于目标分数时重做，attempt为第几次作答
+		for attempt := 1; ; attempt++ {
+			paper, err := answerWork(userCache, work, answer, user.CoursesCustom.AutoExam)
+			if err != nil {
+				lg.Print(lg.INFO, "[", lg.Green, userCache.Account, lg.Default, "] ", "<"+setting.AiSetting.AiType+">", " 【", node.Name, "】 ", lg.BoldRed, "该章节作业无法正常执行，服务器返回信息：", err.Error())
+				break
+			}
+			//审核模式或各AI答案存在分歧时只生成待审核文件，人工确认答案后使用review apply提交
+			if user.CoursesCustom.ExamAutoSubmit == 3 || paper.Disputed {
+				saveReview(user, course, node, paper)
+				break
+			}
+			submitPaper(userCache, paper, user.CoursesCustom.ExamAutoSubmit == 1)
+			var correct map[string]bool
+			if user.CoursesCustom.ExamAutoSubmit == 1 { //交卷后从批改结果中学习正确答案
+				correct = learnPaper(userCache, paper)
+			}
+			auditPaper(user, course.Name, paper, correct)
+			if user.CoursesCustom.ExamAutoSubmit != 1 {
+				lg.Print(lg.INFO, "[", lg.Green, userCache.Account, lg.Default, "] ", "<"+setting.AiSetting.AiType+">", " 【", node.Name, "】", lg.Green, "AI考试完毕,,请自行前往主页提交试卷")
+				break
+			}
 			//打印最终分数
 			s, error := yinghua.WorkedFinallyScoreAction(userCache, work)
 			if error != nil {
 				lg.Print(lg.INFO, "[", lg.Green, userCache.Account, lg.Default, "] ", "<"+setting.AiSetting.AiType+">", " 【", node.Name, "】 ", lg.BoldRed, error)
-				continue
+				break
 			}
 			lg.Print(lg.INFO, "[", lg.Green, userCache.Account, lg.Default, "] ", "<"+setting.AiSetting.AiType+">", " 【", node.Name, "】", lg.Green, "章节作业AI答题完毕，最高分：", s, "分", " 试卷总分：", fmt.Sprintf("%.2f分", work.Score))
-		} else {
-			lg.Print(lg.INFO, "[", lg.Green, userCache.Account, lg.Default, "] ", "<"+setting.AiSetting.AiType+">", " 【", node.Name, "】", lg.Green, "AI考试完毕,,请自行前往主页提交试卷")
+			if !belowTarget(s, targetScore) || attempt > user.CoursesCustom.GetRetakeLimit() || !workAttemptsLeft(userCache, work) {
+				break
+			}
+			hints.add(paper, correct)
+			lg.Print(lg.INFO, "[", lg.Green, userCache.Account, lg.Default, "] ", "<"+setting.AiSetting.AiType+">", " 【", node.Name, "】", "【", work.Title, "】", lg.Yellow, "得分低于目标分数", strconv.FormatFloat(targetScore, 'f', -1, 64), "分，开始第", strconv.Itoa(attempt), "次重做")
 		}
 	}
 
 }
 
 // examAction 考试处理逻辑
-func examAction(setting config.Setting, user *config.Users, userCache *yinghuaApi.YingHuaUserCache, node yinghua.YingHuaNode) {
-	if user.CoursesCustom.AutoExam == 0 { //是否打开了自动考试开关
+func examAction(setting config.Setting, user *config.Users, userCache *yinghuaApi.YingHuaUserCache, course *yinghua.YingHuaCourse, node yinghua.YingHuaNode) {
+	if !user.CoursesCustom.TaskEnabled(config.TaskExam) { //是否打开了自动考试开关和对应任务开关
 		return
 	}
 	if !node.TabExam { //过滤非考试节点
 		return
 	}
 
-	if user.CoursesCustom.AutoExam == 1 {
-		//检测AI可用性
-		err := aiq.AICheck(setting.AiSetting.AiUrl, setting.AiSetting.Model, setting.AiSetting.APIKEY, setting.AiSetting.AiType)
-		if err != nil {
-			lg.Print(lg.INFO, lg.BoldRed, "<"+setting.AiSetting.AiType+">", "AI不可用，错误信息："+err.Error())
-			os.Exit(0)
-		}
-	}
-
-	if user.CoursesCustom.AutoExam == 2 {
-		err := external.CheckApiQueRequest(setting.ApiQueSetting.Url, 3, nil)
-		if err != nil {
-			lg.Print(lg.INFO, lg.BoldRed, "外置题库不可用，错误信息："+err.Error())
-			os.Exit(0)
-		}
+	//答题依赖已在启动时检测，不可用时跳过答题继续学习
+	if ok, err := health.Ready(user.CoursesCustom.AnswerSources()); !ok {
+		lg.Print(lg.INFO, "[", lg.Green, userCache.Account, lg.Default, "] ", " 【", node.Name, "】 ", lg.BoldRed, "答案来源不可用，跳过该章节考试：", err.Error())
+		return
 	}
 
 	//获取作业详细信息
-	detailAction, _ := yinghua.ExamDetailAction(userCache, node.Id)
+	detailAction, _ := retry.DoValue(retry.Get(user.AccountType), "["+userCache.Account+"] ", func() ([]yinghua.YingHuaExam, error) {
+		return yinghua.ExamDetailAction(userCache, node.Id)
+	})
 	////{"_code":9,"status":false,"msg":"考试测试时间还未开始","result":{}}
 	if len(detailAction) == 0 { //过滤没有考试内容的
 		return
 	}
 	//开始考试
 	modelLog.ModelPrint(setting.BasicSetting.LogModel == 0, lg.INFO, "[", lg.Green, userCache.Account, lg.Default, "] ", "<"+setting.AiSetting.AiType+">", lg.Default, " 【"+node.Name+"】 ", lg.Yellow, "正在AI自动考试...")
+	hints := retakeHints{}
+	answer := newAnswerer(setting, user, userCache, course.Name, hints)
+	targetScore := user.CoursesCustom.GetTargetScore(course.Name)
 	for _, exam := range detailAction {
-		//err := yinghua.StartExamAction(userCache, exam, setting.AiSetting.AiUrl, setting.AiSetting.Model, setting.AiSetting.APIKEY, setting.AiSetting.AiType, user.CoursesCustom.ExamAutoSubmit)
-		var err error
-		switch user.CoursesCustom.AutoExam {
-		case 1:
-			err = yinghua.StartExamAction(userCache, exam, setting.AiSetting.AiUrl, setting.AiSetting.Model, setting.AiSetting.APIKEY, setting.AiSetting.AiType, user.CoursesCustom.ExamAutoSubmit)
-			break
-		case 2:
-			err = yinghua.StartExamForExternalAction(userCache, exam, setting.ApiQueSetting.Url, user.CoursesCustom.ExamAutoSubmit)
-			break
-		}
-		if err != nil {
-			lg.Print(lg.INFO, "[", lg.Green, userCache.Account, lg.Default, "] ", "<"+setting.AiSetting.AiType+">", " 【", node.Name, "】 ", lg.BoldRed, "该考试无法正常执行，服务器返回信息：", err.Error())
+		if reviewPending(user, node, "exam", exam.ExamId, exam.Title) {
 			continue
 		}
+		//得分低于目标分数且平台还允许作答时重考
+		for attempt := 1; ; attempt++ {
+			paper, err := answerExam(userCache, exam, answer, user.CoursesCustom.AutoExam)
+			if err != nil && attempt > 1 { //重考时开始考试失败多为平台不允许再考，按正常结束处理
+				lg.Print(lg.INFO, "[", lg.Green, userCache.Account, lg.Default, "] ", "<"+setting.AiSetting.AiType+">", " 【", node.Name, "】", "【", exam.Title, "】", lg.Yellow, "平台不允许再次考试，停止重考：", err.Error())
+				break
+			}
+			if err != nil {
+				lg.Print(lg.INFO, "[", lg.Green, userCache.Account, lg.Default, "] ", "<"+setting.AiSetting.AiType+">", " 【", node.Name, "】 ", lg.BoldRed, "该考试无法正常执行，服务器返回信息：", err.Error())
+				break
+			}
+			//审核模式或各AI答案存在分歧时只生成待审核文件，人工确认答案后使用review apply提交
+			if user.CoursesCustom.ExamAutoSubmit == 3 || paper.Disputed {
+				saveReview(user, course, node, paper)
+				break
+			}
+			submitPaper(userCache, paper, user.CoursesCustom.ExamAutoSubmit == 1)
+			var correct map[string]bool
+			if user.CoursesCustom.ExamAutoSubmit == 1 { //交卷后从批改结果中学习正确答案
+				correct = learnPaper(userCache, paper)
+			}
+			auditPaper(user, course.Name, paper, correct)
 
-		if user.CoursesCustom.ExamAutoSubmit == 1 {
+			if user.CoursesCustom.ExamAutoSubmit != 1 {
+				lg.Print(lg.INFO, "[", lg.Green, userCache.Account, lg.Default, "] ", "<"+setting.AiSetting.AiType+">", " 【", node.Name, "】", lg.Green, "AI考试完毕,请自行前往主页提交试卷")
+				break
+			}
 			//打印最终分数
 			s, error := yinghua.ExamFinallyScoreAction(userCache, exam)
 			if error != nil {
 				lg.Print(lg.INFO, "[", lg.Green, userCache.Account, lg.Default, "] ", "<"+setting.AiSetting.AiType+">", " 【", node.Name, "】 ", lg.BoldRed, error.Error())
-				continue
+				break
 			}
 			lg.Print(lg.INFO, "[", lg.Green, userCache.Account, lg.Default, "] ", "<"+setting.AiSetting.AiType+">", " 【", node.Name, "】", lg.Green, "AI考试完毕,最终分：", s, "分", " 试卷总分：", fmt.Sprintf("%.2f分", exam.Score))
-		} else {
-			lg.Print(lg.INFO, "[", lg.Green, userCache.Account, lg.Default, "] ", "<"+setting.AiSetting.AiType+">", " 【", node.Name, "】", lg.Green, "AI考试完毕,请自行前往主页提交试卷")
+			if !belowTarget(s, targetScore) || attempt > user.CoursesCustom.GetRetakeLimit() {
+				break
+			}
+			if !examAttemptsLeft(userCache, exam) {
+				lg.Print(lg.INFO, "[", lg.Green, userCache.Account, lg.Default, "] ", "<"+setting.AiSetting.AiType+">", " 【", node.Name, "】", "【", exam.Title, "】", lg.Yellow, "得分低于目标分数，但考试次数已用完，停止重考")
+				break
+			}
+			hints.add(paper, correct)
+			lg.Print(lg.INFO, "[", lg.Green, userCache.Account, lg.Default, "] ", "<"+setting.AiSetting.AiType+">", " 【", node.Name, "】", "【", exam.Title, "】", lg.Yellow, "得分低于目标分数", strconv.FormatFloat(targetScore, 'f', -1, 64), "分，开始第", strconv.Itoa(attempt), "次重考")
 		}
-
 	}
 }
@@ -0,0 +1,22 @@
+package yinghua
+
+import (
+	"yatori-go-console/config"
+	"yatori-go-console/utils/prompt"
+
+	"github.com/yatori-dev/yatori-go-core/api/entity"
+	yinghuaApi "github.com/yatori-dev/yatori-go-core/api/yinghua"
+	"github.com/yatori-dev/yatori-go-core/que-core/aiq"
+	lg "github.com/yatori-dev/yatori-go-core/utils/log"
+)
+
+// customPrompt 按aiSetting.prompts中的模板改写题目描述，没有配置或模板有误时使用内置提示词
+func customPrompt(aiSetting config.AiSetting, user *config.Users, userCache *yinghuaApi.YingHuaUserCache, courseName, title string, topic *entity.YingHuaExamTopic, message aiq.AIChatMessages) aiq.AIChatMessages {
+	text := aiSetting.Prompt(user.AccountType, prompt.Kind(topic.Type))
+	res, err := prompt.Apply(message, text, prompt.Data{Platform: user.AccountType, Account: user.Account, Course: courseName, Title: title,
+		Type: topic.Type, Content: topic.Content, Options: topic.Options})
+	if err != nil {
+		lg.Print(lg.INFO, `[`, userCache.Account, `] `, lg.BoldRed, "提示词模板有误，使用内置提示词：", err.Error())
+	}
+	return res
+}
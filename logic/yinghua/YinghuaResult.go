@@ -0,0 +1,79 @@
+package yinghua
+
+import (
+	"encoding/json"
+	"errors"
+	"regexp"
+	"time"
+	"yatori-go-console/utils/result"
+	"yatori-go-console/utils/retry"
+)
+
+// submitResult 英华提交学时接口的返回
+// {"_code":0,"status":true,"msg":"提交学时成功!","result":{"data":{"studyId":123456}}}
+type submitResult struct {
+	Code   int    `json:"_code"`
+	Status bool   `json:"status"`
+	Msg    string `json:"msg"`
+	Result struct {
+		Data struct {
+			StudyId int `json:"studyId"`
+		} `json:"data"`
+	} `json:"result"`
+}
+
+// {"_code":9,"status":false,"msg":"该课程解锁时间【2024-11-14 12:00:00】未到!","result":{}}
+var unlockReg = regexp.MustCompile(`解锁时间【([^】]*)】未到`)
+
+// 英华提示信息的分类规则
+var resultRules = []result.Rule{
+	{Kind: result.SessionExpired, Keywords: []string{"登录超时", "请重新登录"}},
+	{Kind: result.RateLimited, Keywords: []string{"频繁", "太快"}},
+	{Kind: result.Detected, Keywords: []string{"并行播放"}},
+	{Kind: result.Closed, Keywords: []string{"已结束", "已截止"}},
+	{Kind: result.NotStarted, Keywords: []string{"还未开始", "未开始"}},
+}
+
+func init() {
+	retry.RegisterClassifier("YINGHUA", func(err error) retry.Class {
+		return result.RetryClass(classifyError(err))
+	})
+}
+
+// classifyMsg 根据英华返回的提示信息分类
+func classifyMsg(msg string) *result.Error {
+	if m := unlockReg.FindStringSubmatch(msg); m != nil {
+		until, err := time.ParseInLocation("2006-01-02 15:04:05", m[1], time.Local)
+		if err != nil { //解析不到时不带解锁时间，由调用方改用节点上的解锁时间或放弃入队
+			return result.NewLocked(time.Time{}, msg+"（解锁时间解析失败："+err.Error()+"）")
+		}
+		return result.NewLocked(until, msg)
+	}
+	return result.New(result.Match(msg, resultRules), msg)
+}
+
+// classifyError 将英华接口返回的错误转为分类错误
+func classifyError(err error) error {
+	var e *result.Error
+	if errors.As(err, &e) {
+		return err
+	}
+	return classifyMsg(err.Error())
+}
+
+// decodeSubmit 解析提交学时的返回，失败时返回分类后的错误
+func decodeSubmit(sub string, err error) (submitResult, error) {
+	var res submitResult
+	if err != nil {
+		return res, classifyMsg(err.Error())
+	}
+	//result字段在失败时可能不是对象，所以这里只关心msg有没有解析出来
+	json.Unmarshal([]byte(sub), &res)
+	if res.Msg == "" {
+		return res, result.New(result.Unknown, "提交状态异常，msg 字段为空或格式错误："+sub)
+	}
+	if res.Msg != "提交学时成功!" {
+		return res, classifyMsg(res.Msg)
+	}
+	return res, nil
+}
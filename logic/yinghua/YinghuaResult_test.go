@@ -0,0 +1,56 @@
+package yinghua
+
+import (
+	"strings"
+	"testing"
+	"time"
+	"yatori-go-console/utils/result"
+	"yatori-go-console/utils/retry"
+)
+
+func TestClassifyMsg(t *testing.T) {
+	cases := []struct {
+		msg   string
+		kind  result.Kind
+		class retry.Class
+	}{
+		{"该课程解锁时间【2024-11-14 12:00:00】未到!", result.Locked, retry.Permanent},
+		{"课程还未开始!", result.NotStarted, retry.Permanent},
+		{"考试已结束", result.Closed, retry.Permanent},
+		{"作业已截止", result.Closed, retry.Permanent},
+		{"不允许并行播放", result.Detected, retry.Permanent},
+		{"请求过于频繁", result.RateLimited, retry.Retryable},
+		{"登录超时", result.SessionExpired, retry.Retryable},
+		{"网络错误", result.Unknown, retry.Retryable},
+	}
+	for _, c := range cases {
+		err := classifyMsg(c.msg)
+		if err.Kind != c.kind {
+			t.Errorf("%q 分类为%v, want %v", c.msg, err.Kind, c.kind)
+		}
+		if got := result.RetryClass(err); got != c.class {
+			t.Errorf("%q 重试类别为%v, want %v", c.msg, got, c.class)
+		}
+	}
+	//解锁时间解析失败时仍为Locked，但不带解锁时间，提示中带上解析失败原因
+	bad := classifyMsg("该课程解锁时间【明天】未到!")
+	if _, ok := result.LockedUntil(bad); bad.Kind != result.Locked || ok || !strings.Contains(bad.Error(), "解锁时间解析失败") {
+		t.Errorf("解锁时间解析失败时 = %v", bad)
+	}
+	until, ok := result.LockedUntil(classifyMsg("该课程解锁时间【2024-11-14 12:00:00】未到!"))
+	if want := time.Date(2024, 11, 14, 12, 0, 0, 0, time.Local); !ok || !until.Equal(want) {
+		t.Errorf("解锁时间 = %v %v, want %v", until, ok, want)
+	}
+}
+
+func TestDecodeSubmit(t *testing.T) {
+	if _, err := decodeSubmit(`{"_code":0,"status":true,"msg":"提交学时成功!","result":{"data":{"studyId":1}}}`, nil); err != nil {
+		t.Errorf("提交成功不应返回错误：%v", err)
+	}
+	if _, err := decodeSubmit(`{"_code":9,"status":false,"msg":"课程已结束","result":[]}`, nil); !result.Is(err, result.Closed) {
+		t.Errorf("decodeSubmit = %v, want Closed", err)
+	}
+	if _, err := decodeSubmit(`<html>`, nil); !result.Is(err, result.Unknown) {
+		t.Errorf("decodeSubmit = %v, want Unknown", err)
+	}
+}
@@ -0,0 +1,121 @@
+package yinghua
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+	"yatori-go-console/utils/qbank"
+
+	"github.com/thedevsaddam/gojsonq"
+	"github.com/yatori-dev/yatori-go-core/aggregation/yinghua"
+	"github.com/yatori-dev/yatori-go-core/api/entity"
+	yinghuaApi "github.com/yatori-dev/yatori-go-core/api/yinghua"
+	"github.com/yatori-dev/yatori-go-core/que-core/aiq"
+)
+
+// retakeHints 重做时的历史答案，key为题库键，value为之前提交过但整份答卷未达到目标分数的答案
+type retakeHints map[string][][]string
+
+// topicKey 题目在题库中的键
+func topicKey(topic *entity.YingHuaExamTopic) string {
+	return qbank.Key(topic.Type, topic.Content, topic.Options)
+}
+
+// add 记录一份未达到目标分数的答卷中每道题的答案，correct为批改结果，已确认答对的题不记录
+func (h retakeHints) add(paper workPaper, correct map[string]bool) {
+	for i := range paper.Topics {
+		topic := &paper.Topics[i]
+		if len(topic.Answers) == 0 || correct[topic.Index] { //批改结果确认答对的题不作为错误答案提示
+			continue
+		}
+		key := topicKey(topic)
+		h[key] = append(h[key], append([]string(nil), topic.Answers...))
+	}
+}
+
+// has 这道题是否在之前未达标的答卷中作答过
+func (h retakeHints) has(topic *entity.YingHuaExamTopic) bool {
+	return len(h[topicKey(topic)]) > 0
+}
+
+// withHint 在提问前加入历史答案作为反向提示，让AI避开可能错误的答案
+func (h retakeHints) withHint(message aiq.AIChatMessages, topic *entity.YingHuaExamTopic) aiq.AIChatMessages {
+	history := h[topicKey(topic)]
+	if len(history) == 0 || len(message.Messages) == 0 {
+		return message
+	}
+	var tried []string
+	for _, answers := range history {
+		data, _ := json.Marshal(answers)
+		tried = append(tried, string(data))
+	}
+	hint := aiq.Message{Role: "system", Content: "注意：这道题之前回答过" + strings.Join(tried, "、") + "，整份答卷得分未达到要求，这些答案可能是错误的，请重新思考后作答，回答格式要求不变。"}
+	last := len(message.Messages) - 1
+	messages := append([]aiq.Message(nil), message.Messages[:last]...)
+	messages = append(messages, hint, message.Messages[last])
+	return aiq.AIChatMessages{Messages: messages}
+}
+
+// belowTarget 得分是否低于目标分数，目标分数为0时不重做
+func belowTarget(score string, target float64) bool {
+	if target <= 0 {
+		return false
+	}
+	value, err := strconv.ParseFloat(score, 64)
+	return err == nil && value < target
+}
+
+// workAttemptsLeft 作业是否还有作答次数，平台没有限制次数时视为还有
+func workAttemptsLeft(userCache *yinghuaApi.YingHuaUserCache, work yinghua.YingHuaWork) bool {
+	list, err := yinghua.WorkDetailAction(userCache, work.NodeId)
+	if err != nil {
+		return false
+	}
+	for _, w := range list {
+		if w.WorkId == work.WorkId {
+			return w.Allow <= 0 || w.Frequency < w.Allow
+		}
+	}
+	return false
+}
+
+// examAttemptsLeft 考试是否还有作答次数，core的考试信息里没有次数字段，这里直接读取考试详情接口，
+// 接口没有返回次数时视为还有，交由开始考试接口判断
+func examAttemptsLeft(userCache *yinghuaApi.YingHuaUserCache, exam yinghua.YingHuaExam) bool {
+	jsonStr, err := yinghuaApi.ExamDetailApi(*userCache, exam.NodeId, 3, nil)
+	if err != nil {
+		return false
+	}
+	items, ok := gojsonq.New().JSONString(jsonStr).Find("result.list").([]interface{})
+	if !ok {
+		return false
+	}
+	for _, item := range items {
+		obj, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if url, _ := obj["url"].(string); !strings.Contains(url, "examId="+exam.ExamId) {
+			continue
+		}
+		allow, hasAllow := jsonInt(obj["allow"])
+		frequency, hasFrequency := jsonInt(obj["frequency"])
+		if !hasAllow || !hasFrequency {
+			return true
+		}
+		return allow <= 0 || frequency < allow
+	}
+	return false
+}
+
+// jsonInt 接口里的数字有时是字符串有时是数值，统一转成int
+func jsonInt(value interface{}) (int, bool) {
+	switch v := value.(type) {
+	case float64:
+		return int(v), true
+	case string:
+		n, err := strconv.Atoi(v)
+		return n, err == nil
+	}
+	return 0, false
+}
@@ -0,0 +1,61 @@
+package yinghua
+
+import (
+	"encoding/json"
+	"yatori-go-console/config"
+	"yatori-go-console/utils/review"
+
+	"github.com/yatori-dev/yatori-go-core/aggregation/yinghua"
+	yinghuaApi "github.com/yatori-dev/yatori-go-core/api/yinghua"
+	lg "github.com/yatori-dev/yatori-go-core/utils/log"
+)
+
+// saveReview 将作答完毕的作业或考试写入待审核文件
+func saveReview(user *config.Users, course *yinghua.YingHuaCourse, node yinghua.YingHuaNode, paper workPaper) {
+	item := review.Item{Account: user.Account, Platform: user.AccountType, Kind: paper.Kind, Course: course.Name, Title: node.Name + " " + paper.Title}
+	path, err := review.Save(item, paper.Kind+"_"+paper.Id, paper)
+	if err != nil {
+		lg.Print(lg.INFO, "[", lg.Green, user.Account, lg.Default, "] ", " 【", node.Name, "】 ", lg.BoldRed, "写入待审核文件失败：", err.Error())
+		return
+	}
+	lg.Print(lg.INFO, "[", lg.Green, user.Account, lg.Default, "] ", " 【", node.Name, "】", "【", paper.Title, "】 ", lg.Yellow, "答案已写入待审核文件 ", path, "，人工确认后使用 review apply 提交")
+}
+
+// reviewPending 该作业或考试是否还在等待人工审核，是则跳过，避免重复作答覆盖人工修改中的答案
+func reviewPending(user *config.Users, node yinghua.YingHuaNode, kind, id, title string) bool {
+	if !review.Pending(user.AccountType, user.Account, kind+"_"+id) {
+		return false
+	}
+	lg.Print(lg.INFO, "[", lg.Green, user.Account, lg.Default, "] ", " 【", node.Name, "】", "【", title, "】 ", lg.Yellow, "答案还在等待人工审核，已跳过，确认后使用 review apply 提交")
+	return true
+}
+
+// ApplyReview 登录账号并提交人工审核后的答卷
+func ApplyReview(user config.Users, items []review.Item) {
+	cache := &yinghuaApi.YingHuaUserCache{PreUrl: user.URL, Account: user.Account, Password: user.Password}
+	if err := yinghua.YingHuaLoginAction(cache); err != nil {
+		lg.Print(lg.INFO, "[", lg.Green, user.Account, lg.Default, "] ", lg.BoldRed, "登录失败，待审核答卷未提交：", err.Error())
+		return
+	}
+	for _, item := range items {
+		var paper workPaper
+		if err := json.Unmarshal(item.Payload, &paper); err != nil {
+			lg.Print(lg.INFO, "[", lg.Green, user.Account, lg.Default, "] ", "【", item.Title, "】 ", lg.BoldRed, "待审核文件格式错误：", err.Error())
+			continue
+		}
+		for i := range paper.Topics { //人工修改的答案同样统一格式
+			paper.Topics[i].Answers = normalizeTopic(paper.Topics[i], paper.Topics[i].Answers)
+		}
+		res, ok := submitPaper(cache, paper, true)
+		if !ok { //交卷失败时保留待审核状态，下次review apply重新提交
+			lg.Print(lg.INFO, "[", lg.Green, user.Account, lg.Default, "] ", "【", item.Course, "】", "【", item.Title, "】 ", lg.BoldRed, "审核答卷提交失败，保留待审核状态，服务器返回信息：", res)
+			continue
+		}
+		for i := range paper.Sources { //答案经过人工确认
+			paper.Sources[i] = "review(" + paper.Sources[i] + ")"
+		}
+		auditPaper(&user, item.Course, paper, learnPaper(cache, paper))
+		review.MarkApplied(item, res)
+		lg.Print(lg.INFO, "[", lg.Green, user.Account, lg.Default, "] ", "【", item.Course, "】", "【", item.Title, "】 ", lg.Green, "审核答卷已提交，服务器返回信息：", res)
+	}
+}
@@ -0,0 +1,350 @@
+package yinghua
+
+import (
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+	"yatori-go-console/config"
+	"yatori-go-console/utils/aiusage"
+	"yatori-go-console/utils/consensus"
+	"yatori-go-console/utils/health"
+	"yatori-go-console/utils/normalize"
+	"yatori-go-console/utils/qbank"
+
+	"github.com/thedevsaddam/gojsonq"
+	"github.com/yatori-dev/yatori-go-core/aggregation/yinghua"
+	"github.com/yatori-dev/yatori-go-core/api/entity"
+	yinghuaApi "github.com/yatori-dev/yatori-go-core/api/yinghua"
+	"github.com/yatori-dev/yatori-go-core/que-core/aiq"
+	"github.com/yatori-dev/yatori-go-core/que-core/external"
+	lg "github.com/yatori-dev/yatori-go-core/utils/log"
+)
+
+// topicAnswerer 获取单道题的答案，返回答案来源说明，比如ai:TONGYI/qwen-plus、external、bank(ai)
+type topicAnswerer func(title string, topic *entity.YingHuaExamTopic) (string, error)
+
+// newAnswerer 按照账号配置的答案来源顺序构建答题方法，前一个来源没有答案或答案与选项对不上时使用下一个，答完写回本地题库，
+// hints为重做时之前未达到目标分数的答案
+func newAnswerer(setting config.Setting, user *config.Users, userCache *yinghuaApi.YingHuaUserCache, courseName string, hints retakeHints) topicAnswerer {
+	sources := user.CoursesCustom.AnswerSources()
+	minSimilarity := user.CoursesCustom.MinSimilarity()
+	return func(title string, topic *entity.YingHuaExamTopic) (string, error) {
+		var fallback []string //所有来源都没有答案时使用的随机策略答案
+		var lastErr error
+		chains := sources
+		if qbank.Verified(topic.Type, topic.Content, topic.Options) { //批改确认过的答案优先于其他所有来源
+			chains = append([]string{config.AnswerBank}, sources...)
+		}
+		for _, chain := range chains {
+			if chain != config.AnswerBank && !health.Healthy(chain) { //运行中故障的来源暂时跳过
+				continue
+			}
+			var answers []string
+			source := qbank.SourceAI //写入本地题库时的来源
+			label := chain           //答题记录中的来源说明
+			switch chain {
+			case config.AnswerBank:
+				entry, ok := qbank.Lookup(topic.Type, topic.Content, topic.Options)
+				if !ok {
+					continue
+				}
+				//重做时AI和外挂题库缓存的答案可能就是做错的那个，只信任导入和批改确认过的答案
+				if hints.has(topic) && entry.Source != qbank.SourceImport && entry.Source != qbank.SourceVerified {
+					continue
+				}
+				answers, source = qbank.MatchOptions(entry.Answers, topic.Options), entry.Source
+				label = "bank(" + entry.Source + ")"
+			case config.AnswerExternal:
+				source = qbank.SourceExternal
+				res, err := external.ApiQueRequest(topic.Question, setting.ApiQueSetting.Url, 5, nil)
+				health.Report(health.External, err)
+				if err != nil {
+					lastErr = err
+					continue
+				}
+				if res != nil {
+					answers = res.Answers
+				}
+			case config.AnswerAI:
+				aiMessage := customPrompt(setting.AiSetting, user, userCache, courseName, title, topic, yinghuaApi.AIProblemMessage(title, topic.Question))
+				aiMessage = hints.withHint(aiMessage, topic)
+				aiSetting := setting.AiSetting
+				if aiSetting.ConsensusOn() {
+					err := consensusAnswer(aiSetting, user, userCache, title, topic, aiMessage)
+					if err == nil || errors.Is(err, errDisputed) {
+						return "ai:consensus", err
+					}
+					if errors.Is(err, aiusage.ErrPaused) {
+						return "", err
+					}
+					fallback, lastErr = topic.Answers, err
+					continue
+				}
+				label = "ai:" + aiSetting.Name()
+				aiAnswer, err := aiusage.Chat(user.Account, aiSetting.AiType, aiSetting.AiUrl, aiSetting.Model, aiSetting.APIKEY, aiMessage)
+				if errors.Is(err, aiusage.ErrPaused) {
+					return "", err
+				}
+				if err != nil {
+					lastErr = err
+					continue
+				}
+				parsed, ok := aiTurnAnswer(userCache, aiAnswer, *topic)
+				if !ok { //随机策略的答案不写入本地题库
+					fallback = parsed
+					continue
+				}
+				answers = parsed
+			}
+			if answers = normalizeTopic(*topic, answers); len(answers) == 0 {
+				continue
+			}
+			//判断题的答案常写作“正确”“对”等，与选项文字不一定相同，不做相似度检查
+			if score := qbank.OptionScore(answers, topic.Options); topic.Type != "判断" && score < minSimilarity {
+				lg.Print(lg.INFO, `[`, userCache.Account, `] `, lg.Yellow, "【", title, "】第", topic.Index, "题", chain, "答案与选项相似度过低(", strconv.FormatFloat(score, 'f', 2, 64), ")，尝试下一个答案来源")
+				continue
+			}
+			topic.Answers = answers
+			if chain != config.AnswerBank {
+				qbank.Store(qbank.Entry{Type: topic.Type, Content: topic.Content, Options: topic.Options, Answers: topic.Answers, Source: source})
+			}
+			return label, nil
+		}
+		topic.Answers = fallback
+		if fallback != nil {
+			return "random", lastErr
+		}
+		return "", lastErr
+	}
+}
+
+// errDisputed 共识模式下各AI答案不一致且配置为留给人工审核
+var errDisputed = errors.New("各AI答案不一致")
+
+// consensusAnswer 共识模式答题，同一道题询问所有AI并采用多数答案，答案不一致时记录分歧
+func consensusAnswer(aiSetting config.AiSetting, user *config.Users, userCache *yinghuaApi.YingHuaUserCache, title string, topic *entity.YingHuaExamTopic, aiMessage aiq.AIChatMessages) error {
+	var providers []consensus.Provider
+	for _, p := range aiSetting.AllProviders() {
+		providers = append(providers, consensus.Provider{AiType: p.AiType, AiUrl: p.AiUrl, Model: p.Model, APIKEY: p.APIKEY})
+	}
+	votes, err := consensus.Ask(user.Account, providers, aiMessage, func(raw string) ([]string, bool) {
+		return aiTurnAnswer(userCache, raw, *topic)
+	})
+	if err != nil { //用量超出上限暂停答题
+		return err
+	}
+	res, err := consensus.Decide(votes)
+	if err != nil {
+		topic.Answers, _ = aiTurnAnswer(userCache, "", *topic) //全部失败时沿用随机策略
+		return err
+	}
+	topic.Answers = res.Answers
+	if !res.Agreed {
+		disputed := aiSetting.DisagreeReview == 1
+		consensus.Record(consensus.Disagreement{Account: user.Account, Platform: user.AccountType, Title: title, Type: topic.Type, Content: topic.Content, Options: topic.Options,
+			Votes: votes, Chosen: res.Answers, Review: disputed, Support: res.Support, Total: len(votes)})
+		lg.Print(lg.INFO, `[`, userCache.Account, `] `, lg.Yellow, "【", title, "】第", topic.Index, "题各AI答案不一致，采用", strconv.Itoa(res.Support), "/", strconv.Itoa(len(votes)), "票的答案：", strings.Join(res.Answers, ","))
+		if disputed { //留给人工审核的答案不写入本地题库
+			return errDisputed
+		}
+	}
+	qbank.Store(qbank.Entry{Type: topic.Type, Content: topic.Content, Options: topic.Options, Answers: topic.Answers, Source: qbank.SourceAI})
+	return nil
+}
+
+// workPaper 一份作答完毕的作业或考试，审核模式下会原样写入待审核文件
+type workPaper struct {
+	Kind     string                    `json:"kind"` //work为作业，exam为考试
+	Id       string                    `json:"id"`   //作业或考试id
+	CourseId string                    `json:"courseId"`
+	NodeId   string                    `json:"nodeId"`
+	Title    string                    `json:"title"`
+	AutoExam int                       `json:"autoExam"`           //作答方式，1为AI，2为外挂题库
+	Disputed bool                      `json:"disputed,omitempty"` //共识模式下有题目各AI答案不一致，需人工审核
+	Topics   []entity.YingHuaExamTopic `json:"topics"`             //题目与答案
+	Sources  []string                  `json:"sources,omitempty"`  //每道题的答案来源，与Topics一一对应
+}
+
+// answerWork 开始写作业并逐题获取答案，答案只在本地，还未保存到服务器
+func answerWork(userCache *yinghuaApi.YingHuaUserCache, work yinghua.YingHuaWork, answer topicAnswerer, autoExam int) (workPaper, error) {
+	paper := workPaper{Kind: "work", Id: work.WorkId, CourseId: work.CourseId, NodeId: work.NodeId, Title: work.Title, AutoExam: autoExam}
+	//开始写作业
+	startWork, err := yinghuaApi.StartWork(*userCache, work.CourseId, work.NodeId, work.WorkId, 8, nil)
+	if err != nil {
+		return paper, err
+	}
+	//如果开始写作业状态异常则直接抛错
+	if code, ok := gojsonq.New().JSONString(startWork).Find("_code").(float64); ok && int(code) == 9 {
+		return paper, errors.New(gojsonq.New().JSONString(startWork).Find("msg").(string))
+	}
+	//开始答题
+	api, err := yinghuaApi.GetWorkApi(*userCache, work.NodeId, work.WorkId, 8, nil)
+	if err != nil {
+		return paper, err
+	}
+	//html转结构体
+	paper.Topics = yinghuaApi.TurnExamTopic(api)
+	paper.Sources = make([]string, len(paper.Topics))
+	for i := range paper.Topics {
+		source, err := answer(work.Title, &paper.Topics[i])
+		paper.Sources[i] = source
+		if errors.Is(err, errDisputed) {
+			paper.Disputed = true
+		} else if err != nil {
+			return paper, errors.New("获取答案失败：" + err.Error())
+		}
+	}
+	return paper, nil
+}
+
+// answerExam 开始考试并逐题获取答案，答案只在本地，还未保存到服务器
+func answerExam(userCache *yinghuaApi.YingHuaUserCache, exam yinghua.YingHuaExam, answer topicAnswerer, autoExam int) (workPaper, error) {
+	paper := workPaper{Kind: "exam", Id: exam.ExamId, CourseId: exam.CourseId, NodeId: exam.NodeId, Title: exam.Title, AutoExam: autoExam}
+	//开始考试
+	startExam, err := yinghuaApi.StartExam(*userCache, exam.CourseId, exam.NodeId, exam.ExamId, 10, nil)
+	if err != nil {
+		return paper, err
+	}
+	//如果开始考试状态异常则直接抛错
+	if code, ok := gojsonq.New().JSONString(startExam).Find("_code").(float64); ok && int(code) == 9 {
+		return paper, errors.New(gojsonq.New().JSONString(startExam).Find("msg").(string))
+	}
+	//开始答题
+	topicHtml, err := yinghuaApi.GetExamTopicApi(*userCache, exam.NodeId, exam.ExamId, 8, nil)
+	if err != nil {
+		return paper, err
+	}
+	//html转结构体
+	paper.Topics = yinghuaApi.TurnExamTopic(topicHtml)
+	paper.Sources = make([]string, len(paper.Topics))
+	for i := range paper.Topics {
+		//考试中单题获取答案失败不中断考试，留空或按随机策略作答
+		source, err := answer(exam.Title, &paper.Topics[i])
+		paper.Sources[i] = source
+		if errors.Is(err, errDisputed) {
+			paper.Disputed = true
+		} else if errors.Is(err, aiusage.ErrPaused) { //暂停答题时整份试卷都不提交
+			return paper, err
+		} else if err != nil {
+			lg.Print(lg.INFO, `[`, userCache.Account, `] `, lg.BoldRed, "获取答案异常", exam.Title, "第", paper.Topics[i].Index, "题，返回信息：", err.Error())
+		}
+	}
+	return paper, nil
+}
+
+// submitPaper 逐题保存答案，finish为true时最后交卷，返回交卷时服务器返回信息以及是否交卷成功
+func submitPaper(userCache *yinghuaApi.YingHuaUserCache, paper workPaper, finish bool) (string, bool) {
+	submit := yinghuaApi.SubmitWorkApi
+	saved, finished := "答题保存成功", "提交作业成功"
+	if paper.Kind == "exam" {
+		submit = yinghuaApi.SubmitExamApi
+		finished = "提交试卷成功"
+	}
+	var lastProblem entity.YingHuaExamTopic
+	for _, v := range paper.Topics {
+		answerId := v.AnswerId
+		if paper.Kind == "exam" && paper.AutoExam == 2 { //外挂题库考试沿用原有的提交方式
+			answerId = v.Index
+		}
+		subWorkApi, err := submit(*userCache, paper.Id, answerId, v.Question, "0", 10, nil)
+		if err != nil {
+			lg.Print(lg.INFO, `[`, userCache.Account, `] `, lg.BoldRed, "提交答案异常，返回信息：", err.Error())
+		}
+		//如果提交答案服务器端返回信息异常
+		if gojsonq.New().JSONString(subWorkApi).Find("msg") != saved {
+			lg.Print(lg.INFO, lg.BoldRed, `[`, userCache.Account, `] `, lg.BoldRed, "提交答案异常，返回信息：", subWorkApi, "题目内容：", v.Content, "回答信息：", strings.Join(v.Answers, ","))
+		}
+		lastProblem = v
+	}
+	if !finish {
+		return "", false
+	}
+	//交卷
+	subWorkApi, err := submit(*userCache, paper.Id, lastProblem.AnswerId, lastProblem.Question, "1", 10, nil)
+	if err != nil {
+		lg.Print(lg.INFO, lg.BoldRed, `[`, userCache.Account, `] `, lg.BoldRed, "提交试卷异常，返回信息：", subWorkApi, err.Error())
+	}
+	msg := gojsonq.New().JSONString(subWorkApi).Find("msg")
+	if msg != nil && msg != finished {
+		lg.Print(lg.INFO, lg.BoldRed, `[`, userCache.Account, `] `, lg.BoldRed, "提交试卷异常，返回信息：", subWorkApi)
+	}
+	return subWorkApi, msg == finished
+}
+
+// normalizeTopic 按题型清理答案：选择题选项字母对应到选项，判断题同义词对应到选项，填空题去掉序号并按空拆分
+func normalizeTopic(topic entity.YingHuaExamTopic, answers []string) []string {
+	switch topic.Type {
+	case "单选", "多选":
+		return normalize.Choice(answers, topic.Options)
+	case "判断":
+		return normalize.Judge(answers, topic.Options)
+	case "填空":
+		return normalize.Blanks(answers, strings.Count(topic.Content, "answer_"))
+	}
+	res := make([]string, 0, len(answers))
+	for _, answer := range answers {
+		res = append(res, normalize.Short(answer))
+	}
+	return res
+}
+
+// aiTurnAnswer AI回复转答案，选择类题目会对应到选项内容上，无法解析时返回随机策略的答案且parsed为false
+func aiTurnAnswer(cache *yinghuaApi.YingHuaUserCache, aiAnswer string, v entity.YingHuaExamTopic) (answer []string, parsed bool) {
+	var items []string
+	json.Unmarshal([]byte(aiAnswer), &items)
+	items = normalizeTopic(v, items)
+	if v.Type == "单选" || v.Type == "判断" || v.Type == "多选" {
+		var res []string
+		//直接相同匹配方式
+		for _, item := range items {
+			for _, option := range v.Options {
+				if strings.Contains(strings.ReplaceAll(option, " ", ""), strings.ReplaceAll(item, " ", "")) {
+					res = append(res, option)
+				}
+			}
+		}
+		//如果没有答案，采用第二方案，字符串评判法
+		if len(res) == 0 {
+			for _, item := range items {
+				for _, option := range v.Options {
+					if selectMarkingSystem(item, option) > 0.60 {
+						res = append(res, option)
+					}
+				}
+			}
+		}
+		answer = res
+	} else if v.Type == "填空" || v.Type == "简答" {
+		answer = append(answer, items...)
+	}
+	if len(answer) == 0 {
+		if v.Type == "单选" || v.Type == "判断" {
+			answer = []string{"A"}
+		} else if v.Type == "多选" {
+			answer = []string{"B", "C"}
+		} else if v.Type == "简答" || v.Type == "填空" {
+			lg.Print(lg.INFO, `[`, cache.Account, `] `, lg.BoldRed, "\n题目类型：", v.Type, "\n题目：", v.Content, "\n\nAi回答内容无法解析，因该题为填空或简答题，所以自动留空")
+			return answer, false
+		}
+		lg.Print(lg.INFO, `[`, cache.Account, `] `, lg.BoldRed, "\n题目类型：", v.Type, "\n题目：", v.Content, "\n\nAi回答内容无法解析，使用使用随机答案策略")
+		return answer, false
+	}
+	return answer, true
+}
+
+// selectMarkingSystem 选项评分，返回两段文本的字符重合度
+func selectMarkingSystem(text1, text2 string) float32 {
+	if len(text1) == 0 {
+		return 0
+	}
+	sum := float32(0)
+	for i := range text1 {
+		for j := range text2 {
+			if text1[i] == text2[j] {
+				sum += float32(1)
+			}
+		}
+	}
+	return sum / float32(len(text1))
+}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"fmt"
+	"os"
 	"yatori-go-console/config"
 	"yatori-go-console/logic"
 	"yatori-go-console/utils"
@@ -12,5 +13,24 @@ func main() {
 	utils2.YatoriConsoleInit()       //初始化yatori-console
 	fmt.Println(config.YaotirLogo()) //打印LOGO
 	utils.ShowAnnouncement()         //用于显示公告
-	logic.Lunch()                    //启动yatori-console
+	command := ""
+	if len(os.Args) > 1 {
+		command = os.Args[1]
+	}
+	switch command {
+	case "daemon":
+		logic.Daemon() //守护模式，定时启动
+	case "serve-bank":
+		logic.ServeBank() //本地题库服务
+	case "bank":
+		logic.Bank(os.Args[2:]) //本地题库导入导出
+	case "review":
+		logic.Review(os.Args[2:]) //答卷审核
+	case "answers":
+		logic.Answers(os.Args[2:]) //答题记录查询
+	case "usage":
+		logic.UsageReport(os.Args[2:]) //AI用量查询
+	default:
+		logic.Lunch() //启动yatori-console
+	}
 }
@@ -60,3 +60,14 @@ func SendMail(host string, port int, userName, password, mail string) {
 		panic(err)
 	}
 }
+
+// CheckMail 检查SMTP服务能否正常登录
+func CheckMail(host string, port int, userName, password string) error {
+	d := gomail.NewDialer(host, port, userName, password)
+	d.TLSConfig = &tls.Config{InsecureSkipVerify: true}
+	closer, err := d.Dial()
+	if err != nil {
+		return err
+	}
+	return closer.Close()
+}
@@ -35,3 +35,17 @@ func PlayNoticeSound() {
 	<-done
 	NSMutex.Unlock()
 }
+
+// CheckNoticeSound 检查通知音频能否正常读取
+func CheckNoticeSound() error {
+	f, err := os.Open("./assets/sound/finishNotice.mp3")
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	streamer, _, err := mp3.Decode(f)
+	if err != nil {
+		return err
+	}
+	return streamer.Close()
+}
@@ -0,0 +1,157 @@
+package ailimit
+
+import (
+	"math/rand"
+	"regexp"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/yatori-dev/yatori-go-core/models/ctype"
+	"github.com/yatori-dev/yatori-go-core/que-core/aiq"
+	lg "github.com/yatori-dev/yatori-go-core/utils/log"
+)
+
+// Config AI请求限流配置，所有平台、所有账号共用
+type Config struct {
+	MaxInFlight int           //同时进行的请求数上限，core对AI请求加了全局锁，目前大于1时实际仍是逐个请求
+	PerMinute   int           //每分钟最多请求次数，0为不限制
+	MaxRetries  int           //被限流后最多重试次数，小于0为不重试
+	BaseDelay   time.Duration //被限流且没有给出等待时间时第一次重试前的等待，之后每次翻倍
+	MaxDelay    time.Duration //单次等待时间上限
+}
+
+// DefaultConfig 默认配置，逐个请求、不限每分钟次数，被限流时最多重试3次
+var DefaultConfig = Config{MaxInFlight: 1, MaxRetries: 3, BaseDelay: 10 * time.Second, MaxDelay: 2 * time.Minute}
+
+var (
+	limitMut sync.Mutex
+	config   = DefaultConfig
+	slots    = make(chan struct{}, DefaultConfig.MaxInFlight) //同时请求的名额，排队的请求按先后顺序获得
+	recent   []time.Time                                      //最近一分钟内的请求时间
+	cooldown time.Time                                        //被限流后所有请求暂停到这个时间
+)
+
+// Set 设置限流配置，为0的项沿用默认值，需在第一次请求前调用
+func Set(c Config) {
+	if c.MaxInFlight <= 0 {
+		c.MaxInFlight = DefaultConfig.MaxInFlight
+	}
+	if c.MaxRetries == 0 {
+		c.MaxRetries = DefaultConfig.MaxRetries
+	}
+	if c.BaseDelay <= 0 {
+		c.BaseDelay = DefaultConfig.BaseDelay
+	}
+	if c.MaxDelay <= 0 {
+		c.MaxDelay = DefaultConfig.MaxDelay
+	}
+	if c.MaxDelay < c.BaseDelay {
+		c.MaxDelay = c.BaseDelay
+	}
+	limitMut.Lock()
+	defer limitMut.Unlock()
+	config = c
+	slots = make(chan struct{}, c.MaxInFlight)
+}
+
+var (
+	//平台限流时常见的返回信息
+	limitedReg = regexp.MustCompile(`(?i)\b429\b|rate[ _-]?limit|too many requests|throttl|limit_requests|requests? limit|请求过于频繁|请求频率|限流|频率超限|超过.{0,6}(速率|频率|限制)`)
+	//返回信息中给出的等待时间，比如retry after 20 seconds、Retry-After: 20、请20秒后重试
+	retryAfterReg = regexp.MustCompile(`(?i)retry[ _-]?after\D{0,10}(\d+(?:\.\d+)?)|(\d+(?:\.\d+)?)\s*(?:s|秒)\S{0,3}后?重试`)
+)
+
+// RateLimited 判断错误是否为被平台限流，第二个返回值为平台给出的等待时间，没有给出时为0
+func RateLimited(err error) (bool, time.Duration) {
+	if err == nil || !limitedReg.MatchString(err.Error()) {
+		return false, 0
+	}
+	match := retryAfterReg.FindStringSubmatch(err.Error())
+	if match == nil {
+		return true, 0
+	}
+	value := match[1]
+	if value == "" {
+		value = match[2]
+	}
+	seconds, _ := strconv.ParseFloat(value, 64)
+	return true, time.Duration(seconds * float64(time.Second))
+}
+
+// wait 等待限流暂停结束并占用一个每分钟请求名额
+func wait() {
+	for {
+		limitMut.Lock()
+		now := time.Now()
+		var until time.Time
+		if now.Before(cooldown) {
+			until = cooldown
+		} else {
+			//清理一分钟以前的请求记录
+			kept := recent[:0]
+			for _, t := range recent {
+				if now.Sub(t) < time.Minute {
+					kept = append(kept, t)
+				}
+			}
+			recent = kept
+			if config.PerMinute <= 0 || len(recent) < config.PerMinute {
+				recent = append(recent, now)
+				limitMut.Unlock()
+				return
+			}
+			until = recent[0].Add(time.Minute)
+		}
+		limitMut.Unlock()
+		time.Sleep(time.Until(until))
+	}
+}
+
+// backoff 被限流后第attempt次重试前的等待时间，平台给出等待时间时优先使用
+func backoff(attempt int, after time.Duration) time.Duration {
+	limitMut.Lock()
+	c := config
+	limitMut.Unlock()
+	if after > 0 {
+		return after
+	}
+	delay := c.BaseDelay << attempt
+	if delay <= 0 || delay > c.MaxDelay {
+		delay = c.MaxDelay
+	}
+	//±20%随机抖动，避免所有请求同时恢复
+	return time.Duration(float64(delay) * (0.8 + rand.Float64()*0.4))
+}
+
+// Do 排队执行一次AI请求，被平台限流时所有请求一起暂停，等待后重试
+func Do(call func() (string, error)) (string, error) {
+	limitMut.Lock()
+	c, sem := config, slots
+	limitMut.Unlock()
+	sem <- struct{}{}
+	defer func() { <-sem }()
+	for attempt := 0; ; attempt++ {
+		wait()
+		raw, err := call()
+		limited, after := RateLimited(err)
+		if !limited || attempt >= c.MaxRetries {
+			return raw, err
+		}
+		delay := backoff(attempt, after)
+		limitMut.Lock()
+		if until := time.Now().Add(delay); until.After(cooldown) {
+			cooldown = until
+		}
+		limitMut.Unlock()
+		lg.Print(lg.INFO, lg.Yellow, "AI请求被限流，", delay.Round(time.Second).String(), "后重试(", strconv.Itoa(attempt+1), "/", strconv.Itoa(c.MaxRetries), ")")
+	}
+}
+
+// Check 经过限流的AI可用性检测
+func Check(url, model, apiKey string, aiType ctype.AiType) error {
+	_, err := Do(func() (string, error) {
+		return "", aiq.AICheck(url, model, apiKey, aiType)
+	})
+	return err
+}
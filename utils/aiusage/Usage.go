@@ -0,0 +1,275 @@
+package aiusage
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+	"unicode"
+	"yatori-go-console/utils/ailimit"
+	"yatori-go-console/utils/health"
+
+	"github.com/yatori-dev/yatori-go-core/models/ctype"
+	"github.com/yatori-dev/yatori-go-core/que-core/aiq"
+	lg "github.com/yatori-dev/yatori-go-core/utils/log"
+)
+
+// UsagePath 每日AI用量记录位置，按日期和账号累计，重启后每日上限依然有效
+var UsagePath = "./assets/usage/ai_usage.json"
+
+// Usage 一段时间内的AI用量，core不返回平台的token统计，token数按请求和回复的文字估算
+type Usage struct {
+	Requests         int     `json:"requests"`         //请求次数，包含失败的请求
+	Failed           int     `json:"failed"`           //失败的请求次数
+	PromptTokens     int     `json:"promptTokens"`     //估算的输入token数
+	CompletionTokens int     `json:"completionTokens"` //估算的输出token数
+	Cost             float64 `json:"cost"`             //按配置价格估算的费用
+}
+
+// Tokens 输入输出token合计
+func (u Usage) Tokens() int {
+	return u.PromptTokens + u.CompletionTokens
+}
+
+// add 累加一次请求的用量
+func (u *Usage) add(o Usage) {
+	u.Requests += o.Requests
+	u.Failed += o.Failed
+	u.PromptTokens += o.PromptTokens
+	u.CompletionTokens += o.CompletionTokens
+	u.Cost += o.Cost
+}
+
+// Limits 价格与用量上限，为0的上限代表不限制
+type Limits struct {
+	InputPrice    float64 //每千输入token价格
+	OutputPrice   float64 //每千输出token价格
+	RunRequests   int     //本次运行所有账号合计最多请求次数
+	RunTokens     int     //本次运行所有账号合计最多token数
+	RunCost       float64 //本次运行所有账号合计最多费用
+	DailyRequests int     //每个账号每天最多请求次数
+	DailyTokens   int     //每个账号每天最多token数
+	DailyCost     float64 //每个账号每天最多费用
+	Pause         bool    //超出上限后暂停答题，为false时跳过AI改用其他答案来源
+}
+
+// ErrExceeded 用量超出上限，AI不再请求
+var ErrExceeded = errors.New("AI用量已达上限")
+
+// ErrPaused 用量超出上限且配置为暂停答题，调用方应放弃本次作答，不保存也不提交
+var ErrPaused = errors.New("AI用量已达上限，暂停答题")
+
+// limitError 超出上限的具体原因，可以用errors.Is匹配ErrExceeded和ErrPaused
+type limitError struct {
+	reason string
+	pause  bool
+}
+
+func (e limitError) Error() string {
+	if e.pause {
+		return ErrPaused.Error() + "：" + e.reason
+	}
+	return ErrExceeded.Error() + "：" + e.reason
+}
+
+func (e limitError) Is(target error) bool {
+	return target == ErrExceeded || (e.pause && target == ErrPaused)
+}
+
+var (
+	usageMut sync.Mutex
+	loadOnce sync.Once
+	limits   Limits
+	run      Usage                            //本次运行合计
+	accounts = map[string]*Usage{}            //本次运行各账号用量
+	daily    = map[string]map[string]*Usage{} //每日各账号用量，key为日期和账号
+	warned   = map[string]bool{}              //已经提示过的上限，避免每道题都打印
+)
+
+// SetPath 设置用量记录文件位置，需在第一次读写前调用
+func SetPath(path string) {
+	if path != "" {
+		UsagePath = path
+	}
+}
+
+// SetLimits 设置价格与用量上限
+func SetLimits(l Limits) {
+	usageMut.Lock()
+	defer usageMut.Unlock()
+	limits = l
+}
+
+// load 第一次使用时读取每日用量
+func load() {
+	loadOnce.Do(func() {
+		content, err := os.ReadFile(UsagePath)
+		if err != nil {
+			return
+		}
+		json.Unmarshal(content, &daily)
+	})
+}
+
+// save 将每日用量写回文件，先写临时文件再替换
+func save() error {
+	data, err := json.MarshalIndent(daily, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(filepath.Dir(UsagePath), 0755); err != nil {
+		return err
+	}
+	tmp := UsagePath + ".tmp"
+	if err = os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, UsagePath)
+}
+
+// today 当天的日期键
+func today() string {
+	return time.Now().Format("2006-01-02")
+}
+
+// Estimate 估算文本的token数，中日韩文字按每字1个，其余按每4个字符1个
+func Estimate(text string) int {
+	wide, other := 0, 0
+	for _, r := range text {
+		if unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul) {
+			wide++
+		} else {
+			other++
+		}
+	}
+	return wide + (other+3)/4
+}
+
+// estimateMessages 估算一组消息的token数，每条消息额外按4个token计算角色等格式开销
+func estimateMessages(messages aiq.AIChatMessages) int {
+	tokens := 0
+	for _, m := range messages.Messages {
+		tokens += Estimate(m.Content) + 4
+	}
+	return tokens
+}
+
+// exceeded 检查用量是否超出上限，返回超出的原因，调用前需持有锁
+func exceeded(account string) string {
+	if limits.RunRequests > 0 && run.Requests >= limits.RunRequests {
+		return "本次运行请求次数达到" + strconv.Itoa(limits.RunRequests)
+	}
+	if limits.RunTokens > 0 && run.Tokens() >= limits.RunTokens {
+		return "本次运行token数达到" + strconv.Itoa(limits.RunTokens)
+	}
+	if limits.RunCost > 0 && run.Cost >= limits.RunCost {
+		return "本次运行费用达到" + strconv.FormatFloat(limits.RunCost, 'f', -1, 64)
+	}
+	day := Usage{}
+	if u, ok := daily[today()][account]; ok {
+		day = *u
+	}
+	if limits.DailyRequests > 0 && day.Requests >= limits.DailyRequests {
+		return "今日请求次数达到" + strconv.Itoa(limits.DailyRequests)
+	}
+	if limits.DailyTokens > 0 && day.Tokens() >= limits.DailyTokens {
+		return "今日token数达到" + strconv.Itoa(limits.DailyTokens)
+	}
+	if limits.DailyCost > 0 && day.Cost >= limits.DailyCost {
+		return "今日费用达到" + strconv.FormatFloat(limits.DailyCost, 'f', -1, 64)
+	}
+	return ""
+}
+
+// Check 检查账号是否还能请求AI，超出上限时返回可用errors.Is匹配ErrExceeded的错误，每种上限只提示一次
+func Check(account string) error {
+	usageMut.Lock()
+	defer usageMut.Unlock()
+	load()
+	reason := exceeded(account)
+	if reason == "" {
+		return nil
+	}
+	if key := account + "|" + reason; !warned[key] {
+		warned[key] = true
+		action := "跳过AI，改用其他答案来源"
+		if limits.Pause {
+			action = "暂停答题"
+		}
+		lg.Print(lg.INFO, "[", lg.Green, account, lg.Default, "] ", lg.BoldRed, "AI用量", reason, "，", action)
+	}
+	return limitError{reason: reason, pause: limits.Pause}
+}
+
+// record 记录一次请求的用量
+func record(account string, u Usage) {
+	usageMut.Lock()
+	defer usageMut.Unlock()
+	load()
+	u.Cost = (float64(u.PromptTokens)*limits.InputPrice + float64(u.CompletionTokens)*limits.OutputPrice) / 1000
+	run.add(u)
+	if accounts[account] == nil {
+		accounts[account] = &Usage{}
+	}
+	accounts[account].add(u)
+	day := today()
+	if daily[day] == nil {
+		daily[day] = map[string]*Usage{}
+	}
+	if daily[day][account] == nil {
+		daily[day][account] = &Usage{}
+	}
+	daily[day][account].add(u)
+	if err := save(); err != nil {
+		lg.Print(lg.INFO, lg.BoldRed, "AI用量记录写入失败：", err.Error())
+	}
+}
+
+// Chat 检查上限后排队请求AI并记录用量，所有AI答题都应经过这里
+func Chat(account string, aiType ctype.AiType, aiUrl, model, apiKey string, messages aiq.AIChatMessages) (string, error) {
+	if err := Check(account); err != nil {
+		return "", err
+	}
+	//经过全局限流排队，被限流重试的每次请求都计入用量
+	return ailimit.Do(func() (string, error) {
+		raw, err := aiq.AggregationAIApi(aiUrl, model, aiType, messages, apiKey)
+		u := Usage{Requests: 1, PromptTokens: estimateMessages(messages)}
+		if err != nil {
+			u.Failed = 1
+		} else {
+			u.CompletionTokens = Estimate(raw)
+		}
+		record(account, u)
+		health.Report(health.AI, err)
+		return raw, err
+	})
+}
+
+// Run 本次运行的合计用量和各账号用量
+func Run() (Usage, map[string]Usage) {
+	usageMut.Lock()
+	defer usageMut.Unlock()
+	res := make(map[string]Usage, len(accounts))
+	for account, u := range accounts {
+		res[account] = *u
+	}
+	return run, res
+}
+
+// Daily 某一天各账号的用量，date格式为2006-01-02，为空时为当天
+func Daily(date string) map[string]Usage {
+	usageMut.Lock()
+	defer usageMut.Unlock()
+	load()
+	if date == "" {
+		date = today()
+	}
+	res := map[string]Usage{}
+	for account, u := range daily[date] {
+		res[account] = *u
+	}
+	return res
+}
@@ -0,0 +1,120 @@
+package audit
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// AuditPath 答题记录文件位置，每行一道题
+var AuditPath = "./assets/audit/answers.jsonl"
+
+// Record 一道题的答题记录
+type Record struct {
+	Time       time.Time `json:"time"`
+	Account    string    `json:"account"`
+	Platform   string    `json:"platform"`
+	Course     string    `json:"course"`
+	Title      string    `json:"title"` //作业或考试名称
+	Kind       string    `json:"kind"`  //work为作业，exam为考试
+	Type       string    `json:"type"`
+	Content    string    `json:"content"`
+	Options    []string  `json:"options,omitempty"`
+	Answers    []string  `json:"answers"`              //最终提交的答案
+	Source     string    `json:"source"`               //答案来源，比如ai:TONGYI/qwen-plus、external、bank(ai)
+	Similarity float64   `json:"similarity,omitempty"` //答案与最接近选项的相似度，只有选择题才有
+	Correct    *bool     `json:"correct,omitempty"`    //平台批改结果，平台不提供时为空
+}
+
+var auditMut sync.Mutex
+
+// Append 追加答题记录
+func Append(records []Record) error {
+	if len(records) == 0 {
+		return nil
+	}
+	auditMut.Lock()
+	defer auditMut.Unlock()
+	if err := os.MkdirAll(filepath.Dir(AuditPath), 0755); err != nil {
+		return err
+	}
+	file, err := os.OpenFile(AuditPath, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	now := time.Now()
+	for _, record := range records {
+		if record.Time.IsZero() {
+			record.Time = now
+		}
+		data, err := json.Marshal(record)
+		if err != nil {
+			return err
+		}
+		if _, err = file.Write(append(data, '\n')); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Filter 查询条件，为空的条件不过滤
+type Filter struct {
+	Account string
+	Course  string //课程名称包含该内容
+	Title   string //作业或考试名称包含该内容
+	Source  string //答案来源以该内容开头
+	Wrong   bool   //只看批改为错误的题
+}
+
+// match 记录是否满足查询条件
+func (f Filter) match(record Record) bool {
+	if f.Account != "" && record.Account != f.Account {
+		return false
+	}
+	if f.Course != "" && !strings.Contains(record.Course, f.Course) {
+		return false
+	}
+	if f.Title != "" && !strings.Contains(record.Title, f.Title) {
+		return false
+	}
+	if f.Source != "" && !strings.HasPrefix(record.Source, f.Source) {
+		return false
+	}
+	if f.Wrong && (record.Correct == nil || *record.Correct) {
+		return false
+	}
+	return true
+}
+
+// Query 按时间顺序查询答题记录
+func Query(f Filter) ([]Record, error) {
+	auditMut.Lock()
+	defer auditMut.Unlock()
+	file, err := os.Open(AuditPath)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	var list []Record
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 1024*1024), 1024*1024)
+	for scanner.Scan() {
+		var record Record
+		if json.Unmarshal(scanner.Bytes(), &record) != nil {
+			continue
+		}
+		if f.match(record) {
+			list = append(list, record)
+		}
+	}
+	return list, scanner.Err()
+}
@@ -0,0 +1,159 @@
+package consensus
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"yatori-go-console/utils/aiusage"
+	"yatori-go-console/utils/qbank"
+
+	"github.com/yatori-dev/yatori-go-core/models/ctype"
+	"github.com/yatori-dev/yatori-go-core/que-core/aiq"
+)
+
+// DisagreementPath 各AI答案不一致的题目记录位置，每行一条json
+var DisagreementPath = "./assets/consensus/disagreements.jsonl"
+
+// Provider 参与投票的单个AI平台
+type Provider struct {
+	AiType ctype.AiType
+	AiUrl  string
+	Model  string
+	APIKEY string
+}
+
+// Vote 单个AI平台的回答
+type Vote struct {
+	Provider string   `json:"provider"`        //AI平台与模型
+	Answers  []string `json:"answers"`         //解析后的答案
+	Error    string   `json:"error,omitempty"` //请求失败或回答无法解析时的信息
+}
+
+// Result 投票结果
+type Result struct {
+	Answers []string //得票最多的答案
+	Votes   []Vote   //所有平台的回答
+	Support int      //多数答案的票数
+	Agreed  bool     //所有平台都给出了相同的答案
+}
+
+// Disagreement 一条分歧记录
+type Disagreement struct {
+	Time     time.Time `json:"time"`
+	Account  string    `json:"account"`
+	Platform string    `json:"platform"`
+	Title    string    `json:"title"` //作业或考试名称
+	Type     string    `json:"type"`
+	Content  string    `json:"content"`
+	Options  []string  `json:"options,omitempty"`
+	Votes    []Vote    `json:"votes"`
+	Chosen   []string  `json:"chosen"`  //最终采用的答案
+	Review   bool      `json:"review"`  //是否留给人工审核
+	Support  int       `json:"support"` //采用答案的票数
+	Total    int       `json:"total"`   //参与投票的平台数
+}
+
+// ErrNoAnswer 没有任何平台给出可用的答案
+var ErrNoAnswer = errors.New("所有AI平台均未给出有效答案")
+
+var recordMut sync.Mutex
+
+// name 平台显示名，比如TONGYI/qwen-plus
+func (p Provider) name() string {
+	if p.Model == "" {
+		return string(p.AiType)
+	}
+	return string(p.AiType) + "/" + p.Model
+}
+
+// Ask 以account的名义依次询问所有平台并记录用量，parse负责把AI回复解析为答案，无法解析时返回false，
+// 用量超出上限且配置为暂停答题时返回aiusage.ErrPaused
+func Ask(account string, providers []Provider, messages aiq.AIChatMessages, parse func(raw string) ([]string, bool)) ([]Vote, error) {
+	votes := make([]Vote, 0, len(providers))
+	for _, p := range providers {
+		vote := Vote{Provider: p.name()}
+		raw, err := aiusage.Chat(account, p.AiType, p.AiUrl, p.Model, p.APIKEY, messages)
+		if errors.Is(err, aiusage.ErrPaused) {
+			return votes, err
+		}
+		if err != nil {
+			vote.Error = err.Error()
+		} else if answers, ok := parse(raw); ok {
+			vote.Answers = answers
+		} else {
+			vote.Error = "回答无法解析：" + raw
+		}
+		votes = append(votes, vote)
+	}
+	return votes, nil
+}
+
+// answerKey 答案归一化后排序，用于判断两个平台的答案是否相同
+func answerKey(answers []string) string {
+	norm := make([]string, 0, len(answers))
+	for _, answer := range answers {
+		norm = append(norm, qbank.Normalize(answer))
+	}
+	sort.Strings(norm)
+	return strings.Join(norm, "|")
+}
+
+// Decide 按多数票选出答案，票数相同时以配置中靠前的平台为准
+func Decide(votes []Vote) (Result, error) {
+	res := Result{Votes: votes}
+	counts := map[string]int{}
+	var order []string
+	first := map[string][]string{}
+	valid := 0
+	for _, vote := range votes {
+		if vote.Error != "" || len(vote.Answers) == 0 {
+			continue
+		}
+		valid++
+		key := answerKey(vote.Answers)
+		if _, ok := counts[key]; !ok {
+			order = append(order, key)
+			first[key] = vote.Answers
+		}
+		counts[key]++
+	}
+	if valid == 0 {
+		return res, ErrNoAnswer
+	}
+	best := order[0]
+	for _, key := range order[1:] {
+		if counts[key] > counts[best] {
+			best = key
+		}
+	}
+	res.Answers = first[best]
+	res.Support = counts[best]
+	res.Agreed = len(order) == 1 && valid == len(votes)
+	return res, nil
+}
+
+// Record 追加一条分歧记录
+func Record(d Disagreement) error {
+	recordMut.Lock()
+	defer recordMut.Unlock()
+	d.Time = time.Now()
+	data, err := json.Marshal(d)
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(filepath.Dir(DisagreementPath), 0755); err != nil {
+		return err
+	}
+	file, err := os.OpenFile(DisagreementPath, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	_, err = file.Write(append(data, '\n'))
+	return err
+}
@@ -0,0 +1,201 @@
+package graded
+
+import (
+	"crypto/tls"
+	"errors"
+	"html"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+	"yatori-go-console/utils/normalize"
+	"yatori-go-console/utils/qbank"
+)
+
+// Item 批改后答卷中一道题的正确答案
+type Item struct {
+	Text    string   //题目所在片段归一化后的文本，用于和作答时的题目对应
+	Answers []string //正确答案，可能是选项字母、选项内容或各空的答案
+	Raw     string   //未拆分的正确答案，简答题整段使用
+}
+
+var (
+	breakReg  = regexp.MustCompile(`(?i)<br\s*/?>|</(p|div|li|dd|dt|tr|h\d)>`)
+	tagReg    = regexp.MustCompile(`<[^>]*>`)
+	scriptReg = regexp.MustCompile(`(?is)<(script|style)[^>]*>.*?</(script|style)>`)
+	answerReg = regexp.MustCompile(`(正确答案|参考答案|标准答案)\s*[:：]\s*`)
+	//答案后面常跟着的其他字段，截到这里为止
+	stopReg  = regexp.MustCompile(`(我的答案|你的答案|学生答案|答案解析|解析|得分|分数)\s*[:：]`)
+	indexReg = regexp.MustCompile(`^\s*(\(\d+\)|（\d+）|第\S{1,3}空\s*[:：]?|\d+[.、．])\s*`)
+)
+
+// toText 将html转为纯文本，块级标签换行，其余标签替换为空格
+func toText(content string) string {
+	content = scriptReg.ReplaceAllString(content, "")
+	content = breakReg.ReplaceAllString(content, "\n")
+	content = tagReg.ReplaceAllString(content, " ")
+	return html.UnescapeString(content)
+}
+
+// Parse 从批改后的答卷html中找出所有正确答案，每个正确答案与上一个正确答案之间的内容视为这道题
+func Parse(content string) []Item {
+	text := toText(content)
+	locs := answerReg.FindAllStringIndex(text, -1)
+	var items []Item
+	start := 0
+	for i, loc := range locs {
+		end := len(text)
+		if i+1 < len(locs) {
+			end = locs[i+1][0]
+		}
+		value := text[loc[1]:end]
+		if line := strings.Index(value, "\n"); line >= 0 {
+			//答案与标签不在同一行时取下一行
+			if strings.TrimSpace(value[:line]) == "" {
+				value = strings.TrimLeft(value, " \t\r\n")
+				if line = strings.Index(value, "\n"); line >= 0 {
+					value = value[:line]
+				}
+			} else {
+				value = value[:line]
+			}
+		}
+		if stop := stopReg.FindStringIndex(value); stop != nil {
+			value = value[:stop[0]]
+		}
+		if answers := split(value); len(answers) > 0 {
+			items = append(items, Item{Text: qbank.Normalize(text[start:loc[0]]), Answers: answers, Raw: strings.TrimSpace(value)})
+		}
+		start = loc[1]
+	}
+	return items
+}
+
+// split 拆分多个空或多个选项的答案，连续的选项字母比如“ABD”留给Resolve拆分
+func split(value string) []string {
+	var answers []string
+	for _, item := range strings.FieldsFunc(value, func(r rune) bool {
+		return r == ';' || r == '；' || r == '|' || r == ',' || r == '，' || r == '、'
+	}) {
+		if item = strings.TrimSpace(indexReg.ReplaceAllString(item, "")); item != "" {
+			answers = append(answers, item)
+		}
+	}
+	return answers
+}
+
+var blankReg = regexp.MustCompile(`（answer_[^）]*）|_{2,}|[(（]\s*[)）]`)
+
+// Matcher 按答卷顺序为题目找对应的正确答案，已对应过的片段不再使用，避免题干相同的题目拿到同一个答案
+type Matcher struct {
+	items []Item
+	used  []bool
+}
+
+// NewMatcher 由批改后答卷的所有正确答案创建
+func NewMatcher(items []Item) *Matcher {
+	return &Matcher{items: items, used: make([]bool, len(items))}
+}
+
+// Find 找出题目对应的正确答案，题目内容按填空处拆开后每一段以及每个选项的内容都要出现在片段里，
+// 取第一个未使用的片段并标记为已使用，unique为false代表还有其他未使用的片段也满足，对应关系不可靠
+func (m *Matcher) Find(content string, options []string) (item Item, ok bool, unique bool) {
+	var parts []string
+	for _, part := range blankReg.Split(content, -1) {
+		if norm := qbank.Normalize(part); norm != "" {
+			parts = append(parts, norm)
+		}
+	}
+	if len(parts) == 0 {
+		return Item{}, false, false
+	}
+	for _, option := range options {
+		if norm := qbank.Normalize(normalize.OptionText(option)); norm != "" {
+			parts = append(parts, norm)
+		}
+	}
+	found := -1
+	for i, candidate := range m.items {
+		if m.used[i] || !containsAll(candidate.Text, parts) {
+			continue
+		}
+		if found >= 0 {
+			m.used[found] = true
+			return m.items[found], true, false
+		}
+		found = i
+	}
+	if found < 0 {
+		return Item{}, false, false
+	}
+	m.used[found] = true
+	return m.items[found], true, true
+}
+
+// containsAll text是否包含所有parts
+func containsAll(text string, parts []string) bool {
+	for _, part := range parts {
+		if !strings.Contains(text, part) {
+			return false
+		}
+	}
+	return true
+}
+
+// Resolve 将正确答案转换为选项内容，options需按选项字母顺序排列，判断题统一为对应的选项或“正确”“错误”
+func Resolve(answers []string, options []string, judge bool) []string {
+	if judge {
+		return normalize.Judge(answers, options)
+	}
+	return normalize.Choice(answers, options)
+}
+
+// Same 提交的答案与正确答案是否一致，不区分顺序
+func Same(answers, correct []string) bool {
+	if len(answers) != len(correct) {
+		return false
+	}
+	seen := map[string]int{}
+	for _, answer := range answers {
+		seen[qbank.Normalize(normalize.OptionText(answer))]++
+	}
+	for _, answer := range correct {
+		key := qbank.Normalize(normalize.OptionText(answer))
+		if seen[key] == 0 {
+			return false
+		}
+		seen[key]--
+	}
+	return true
+}
+
+// Fetch 带上账号cookie获取批改后的答卷页面
+func Fetch(url string, cookies []*http.Cookie) (string, error) {
+	client := &http.Client{
+		Timeout: 30 * time.Second,
+		Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{
+				InsecureSkipVerify: true,
+			},
+		},
+	}
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Add("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36")
+	for _, cookie := range cookies {
+		req.AddCookie(cookie)
+	}
+	res, err := client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return "", errors.New("获取批改结果失败，状态码：" + res.Status)
+	}
+	body, err := io.ReadAll(res.Body)
+	return string(body), err
+}
@@ -0,0 +1,49 @@
+package graded
+
+import "testing"
+
+// gradedHtml 两道题干相同的选择题和一道填空题
+const gradedHtml = `<div>1. 下列说法正确的是</div><ul><li>A. 地球是圆的</li><li>B. 太阳绕地球转</li></ul><p>正确答案：A</p>
+<div>2. 下列说法正确的是</div><ul><li>A. 水往低处流</li><li>B. 月亮会发光</li></ul><p>正确答案：A</p>
+<div>3. 中国的首都是（answer_1）</div><p>正确答案：北京</p>`
+
+func TestMatcherFind(t *testing.T) {
+	items := Parse(gradedHtml)
+	if len(items) != 3 {
+		t.Fatalf("Parse 得到 %d 道题, want 3", len(items))
+	}
+	tests := []struct {
+		content string
+		options []string
+		ok      bool
+		unique  bool
+		answer  string
+	}{
+		//题干相同时按选项对应，不会拿到第一题的答案
+		{"下列说法正确的是", []string{"水往低处流", "月亮会发光"}, true, true, "A"},
+		{"下列说法正确的是", []string{"A. 地球是圆的", "B. 太阳绕地球转"}, true, true, "A"},
+		{"中国的首都是（answer_1）", nil, true, true, "北京"},
+		//已经对应过的片段不再使用
+		{"中国的首都是（answer_1）", nil, false, false, ""},
+		{"下列说法正确的是", []string{"不存在的选项"}, false, false, ""},
+	}
+	m := NewMatcher(items)
+	for i, tt := range tests {
+		item, ok, unique := m.Find(tt.content, tt.options)
+		if ok != tt.ok || unique != tt.unique || (ok && item.Raw != tt.answer) {
+			t.Fatalf("第%d题 Find = %q, %v, %v, want %q, %v, %v", i+1, item.Raw, ok, unique, tt.answer, tt.ok, tt.unique)
+		}
+	}
+}
+
+func TestMatcherAmbiguous(t *testing.T) {
+	m := NewMatcher(Parse(gradedHtml))
+	//只有题干时两道题都满足，按顺序取第一个但标记为不唯一
+	item, ok, unique := m.Find("下列说法正确的是", nil)
+	if !ok || unique || !containsAll(item.Text, []string{"地球是圆的"}) {
+		t.Fatalf("Find = %q, %v, %v, want 第一题且不唯一", item.Text, ok, unique)
+	}
+	if _, ok, unique = m.Find("下列说法正确的是", nil); !ok || !unique {
+		t.Fatalf("第二次 Find = %v, %v, want 剩下的一个且唯一", ok, unique)
+	}
+}
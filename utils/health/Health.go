@@ -0,0 +1,153 @@
+package health
+
+import (
+	"strconv"
+	"sync"
+	"time"
+
+	lg "github.com/yatori-dev/yatori-go-core/utils/log"
+)
+
+// 依赖名称
+const (
+	AI       = "ai"       //AI答题
+	External = "external" //外挂题库
+	SMTP     = "smtp"     //邮件通知
+	Sound    = "sound"    //完成提示音
+)
+
+// names 依赖的中文名，用于日志
+var names = map[string]string{AI: "AI", External: "外挂题库", SMTP: "邮件服务", Sound: "提示音"}
+
+// Policy 运行中依赖故障的处理方式
+type Policy struct {
+	Threshold int           //连续失败多少次视为故障，默认3
+	Recheck   time.Duration //故障后每隔多久重新检测一次，0为不再检测
+}
+
+// DefaultPolicy 默认连续失败3次视为故障，10分钟后重新检测
+var DefaultPolicy = Policy{Threshold: 3, Recheck: 10 * time.Minute}
+
+// status 一个依赖的健康状态
+type status struct {
+	probe    func() error //检测方法
+	err      error        //最近一次故障原因，为空代表正常
+	failures int          //连续失败次数
+	checked  time.Time    //最近一次检测时间
+	checking bool         //正在重新检测，避免多个协程同时检测
+}
+
+var (
+	healthMut sync.Mutex
+	policy    = DefaultPolicy
+	statusMap = map[string]*status{}
+)
+
+// SetPolicy 设置故障处理方式，为0的项沿用默认值
+func SetPolicy(p Policy) {
+	if p.Threshold <= 0 {
+		p.Threshold = DefaultPolicy.Threshold
+	}
+	if p.Recheck < 0 {
+		p.Recheck = 0
+	}
+	healthMut.Lock()
+	defer healthMut.Unlock()
+	policy = p
+}
+
+// Name 依赖的中文名
+func Name(name string) string {
+	if n, ok := names[name]; ok {
+		return n
+	}
+	return name
+}
+
+// Check 注册依赖的检测方法并立即检测一次，返回检测结果
+func Check(name string, probe func() error) error {
+	err := probe()
+	healthMut.Lock()
+	defer healthMut.Unlock()
+	statusMap[name] = &status{probe: probe, err: err, checked: time.Now()}
+	return err
+}
+
+// Healthy 依赖是否可用，没有检测过的依赖视为可用，故障超过重新检测间隔时会重新检测一次
+func Healthy(name string) bool {
+	healthMut.Lock()
+	s, ok := statusMap[name]
+	if !ok || s.err == nil {
+		healthMut.Unlock()
+		return true
+	}
+	if s.probe == nil || policy.Recheck <= 0 || s.checking || time.Since(s.checked) < policy.Recheck {
+		healthMut.Unlock()
+		return false
+	}
+	s.checking = true
+	probe := s.probe
+	healthMut.Unlock()
+
+	err := probe()
+	healthMut.Lock()
+	defer healthMut.Unlock()
+	s.checking, s.checked = false, time.Now()
+	if err == nil {
+		s.err, s.failures = nil, 0
+		lg.Print(lg.INFO, lg.Green, Name(name), "已恢复可用")
+		return true
+	}
+	s.err = err
+	return false
+}
+
+// Ready 按答案来源判断能否答题，来源中的AI和外挂题库全部故障时返回false和其中一个故障原因，只有本地题库时总能答题
+func Ready(sources []string) (bool, error) {
+	var lastErr error
+	remote := false
+	for _, source := range sources {
+		if source != AI && source != External {
+			continue
+		}
+		remote = true
+		if Healthy(source) {
+			return true, nil
+		}
+		if lastErr = Err(source); lastErr == nil { //刚好在检测之间恢复
+			return true, nil
+		}
+	}
+	return !remote, lastErr
+}
+
+// Err 依赖的故障原因，可用时为空
+func Err(name string) error {
+	healthMut.Lock()
+	defer healthMut.Unlock()
+	if s, ok := statusMap[name]; ok {
+		return s.err
+	}
+	return nil
+}
+
+// Report 运行中报告一次依赖调用的结果，连续失败达到阈值时标记为故障，之后按重新检测间隔自动恢复
+func Report(name string, err error) {
+	healthMut.Lock()
+	defer healthMut.Unlock()
+	s, ok := statusMap[name]
+	if !ok {
+		s = &status{}
+		statusMap[name] = s
+	}
+	if err == nil {
+		s.failures = 0
+		return
+	}
+	s.failures++
+	if s.err != nil || s.failures < policy.Threshold {
+		return
+	}
+	s.err, s.checked = err, time.Now()
+	lg.Print(lg.INFO, lg.BoldRed, Name(name), "连续", strconv.Itoa(s.failures), "次失败，暂停答题但继续学习其他内容，错误信息：", err.Error())
+}
@@ -0,0 +1,86 @@
+package health
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+// reset 清空状态并设置策略
+func reset(p Policy) {
+	healthMut.Lock()
+	statusMap = map[string]*status{}
+	healthMut.Unlock()
+	SetPolicy(p)
+}
+
+func TestReportThreshold(t *testing.T) {
+	reset(Policy{Threshold: 3})
+	fail := errors.New("timeout")
+	tests := []struct {
+		err     error
+		healthy bool
+	}{
+		{fail, true},
+		{fail, true},
+		{nil, true}, //成功后连续失败次数清零
+		{fail, true},
+		{fail, true},
+		{fail, false},
+		{fail, false},
+	}
+	for i, tt := range tests {
+		Report(AI, tt.err)
+		if got := Healthy(AI); got != tt.healthy {
+			t.Fatalf("第%d次报告后 Healthy = %v, want %v", i+1, got, tt.healthy)
+		}
+	}
+	if Err(AI) != fail {
+		t.Fatalf("Err = %v, want %v", Err(AI), fail)
+	}
+}
+
+func TestHealthyRecheck(t *testing.T) {
+	tests := []struct {
+		name    string
+		recheck time.Duration
+		probe   error
+		healthy bool
+	}{
+		{"不再检测", 0, nil, false},
+		{"检测恢复", time.Nanosecond, nil, true},
+		{"检测仍失败", time.Nanosecond, errors.New("down"), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reset(Policy{Recheck: tt.recheck})
+			Check(External, func() error { return errors.New("down") })
+			healthMut.Lock()
+			statusMap[External].probe = func() error { return tt.probe }
+			statusMap[External].checked = time.Now().Add(-time.Second)
+			healthMut.Unlock()
+			if got := Healthy(External); got != tt.healthy {
+				t.Fatalf("Healthy = %v, want %v", got, tt.healthy)
+			}
+		})
+	}
+}
+
+func TestReady(t *testing.T) {
+	reset(Policy{})
+	Check(AI, func() error { return errors.New("down") })
+	tests := []struct {
+		sources []string
+		ready   bool
+	}{
+		{[]string{"local"}, true},
+		{[]string{AI}, false},
+		{[]string{"local", AI}, false},
+		{[]string{AI, External}, true}, //外挂题库没有检测过，视为可用
+	}
+	for _, tt := range tests {
+		if got, _ := Ready(tt.sources); got != tt.ready {
+			t.Errorf("Ready(%v) = %v, want %v", tt.sources, got, tt.ready)
+		}
+	}
+}
@@ -0,0 +1,217 @@
+package normalize
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+var (
+	//答案开头常带的说明
+	answerPrefixReg = regexp.MustCompile(`(?i)^\s*(正确答案|参考答案|标准答案|答案|answer)\s*[:：]\s*`)
+	//连续的大写选项字母，比如AC、A、C、A,C、A C
+	lettersReg = regexp.MustCompile(`^[A-Z](\s*[,，、;；/|和及 ]?\s*[A-Z])*$`)
+	//选项开头的字母，比如“A.”“B、”“C)”“D”
+	optionPrefixReg = regexp.MustCompile(`^\s*([A-Za-z])\s*[.、．:：)）]\s*`)
+	//填空答案开头的序号，比如(1)、（1）、1.、①、第一空：、空1：
+	blankIndexReg = regexp.MustCompile(`^\s*(\(\d+\)|（\d+）|\d+\s*[、．)）]|\d+\.\s+|[①-⑳]|第[一二三四五六七八九十\d]+空\s*[:：]?|空\s*\d+\s*[:：])\s*`)
+	//一个答案中多个空的分隔符
+	blankSepReg = regexp.MustCompile(`\s*[;；|]\s*|\s*(\(\d+\)|（\d+）|[①-⑳]|第[一二三四五六七八九十\d]+空\s*[:：]?)\s*`)
+)
+
+// 判断题同义词，比较前统一小写并去掉首尾标点
+var (
+	trueWords  = []string{"对", "正确", "对的", "正确的", "是", "是的", "√", "✓", "✔", "t", "true", "y", "yes", "right"}
+	falseWords = []string{"错", "错误", "错的", "错误的", "不对", "不正确", "否", "×", "✗", "✘", "x", "f", "false", "n", "no", "wrong"}
+)
+
+// 标准的判断题答案
+const (
+	JudgeTrue  = "正确"
+	JudgeFalse = "错误"
+)
+
+// trimPunct 去掉首尾空白和标点，判断题符号√×不算标点
+func trimPunct(text string) string {
+	return strings.TrimFunc(text, func(r rune) bool {
+		return unicode.IsSpace(r) || (unicode.IsPunct(r) && r != '√' && r != '×')
+	})
+}
+
+// stripPrefix 去掉答案开头的“答案：”等说明
+func stripPrefix(answer string) string {
+	return strings.TrimSpace(answerPrefixReg.ReplaceAllString(answer, ""))
+}
+
+// OptionText 去掉选项开头的字母，比如“A. 北京”和英华的“A北京”都返回“北京”
+func OptionText(option string) string {
+	if text := optionPrefixReg.ReplaceAllString(option, ""); text != "" && text != option {
+		return strings.TrimSpace(text)
+	}
+	option = strings.TrimSpace(option)
+	if runes := []rune(option); len(runes) > 1 && runes[0] >= 'A' && runes[0] <= 'Z' && runes[1] > unicode.MaxASCII {
+		return string(runes[1:])
+	}
+	return option
+}
+
+// Letters 拆分连续的选项字母，比如“AC”“A、C”“A,C”都返回[A C]，不是选项字母时返回空
+func Letters(answer string) []string {
+	answer = trimPunct(stripPrefix(answer))
+	if !lettersReg.MatchString(answer) {
+		return nil
+	}
+	var letters []string
+	for _, r := range answer {
+		if r >= 'A' && r <= 'Z' {
+			letters = append(letters, string(r))
+		}
+	}
+	return letters
+}
+
+// Choice 将选择题答案对应到选项，options需按选项字母顺序排列：
+// 与选项内容相同的答案直接使用该选项，选项字母(AC、A、C)按顺序对应选项，带字母前缀的答案(“A. 北京”)按内容或字母对应，
+// 对应不上的答案原样保留，交给后续的相似度匹配，结果去重
+func Choice(answers []string, options []string) []string {
+	var res []string
+	seen := map[string]bool{}
+	add := func(answer string) {
+		if !seen[answer] {
+			seen[answer] = true
+			res = append(res, answer)
+		}
+	}
+	for _, answer := range answers {
+		answer = stripPrefix(answer)
+		if answer == "" {
+			continue
+		}
+		if option, ok := matchOption(answer, options); ok {
+			add(option)
+			continue
+		}
+		if letters := Letters(answer); len(letters) > 0 && lettersInRange(letters, len(options)) {
+			for _, letter := range letters {
+				add(options[letter[0]-'A'])
+			}
+			continue
+		}
+		if match := optionPrefixReg.FindStringSubmatch(answer); match != nil {
+			if index := int(strings.ToUpper(match[1])[0] - 'A'); index < len(options) {
+				add(options[index])
+				continue
+			}
+		}
+		add(answer)
+	}
+	return res
+}
+
+// lettersInRange 所有字母都在选项范围内
+func lettersInRange(letters []string, count int) bool {
+	for _, letter := range letters {
+		if int(letter[0]-'A') >= count {
+			return false
+		}
+	}
+	return true
+}
+
+// matchOption 找出内容相同的选项，忽略字母前缀、空白和首尾标点
+func matchOption(answer string, options []string) (string, bool) {
+	text := trimPunct(OptionText(answer))
+	for _, option := range options {
+		if text == trimPunct(OptionText(option)) || answer == option {
+			return option, true
+		}
+	}
+	return "", false
+}
+
+// JudgeValue 判断题答案表示对还是错，第二个返回值为是否能识别
+func JudgeValue(answer string) (bool, bool) {
+	word := strings.ToLower(trimPunct(stripPrefix(answer)))
+	for _, w := range trueWords {
+		if word == w {
+			return true, true
+		}
+	}
+	for _, w := range falseWords {
+		if word == w {
+			return false, true
+		}
+	}
+	return false, false
+}
+
+// Judge 将判断题答案统一为对应的选项，options为空时统一为“正确”“错误”，
+// 答案为选项字母时按字母对应选项，无法识别的答案原样保留
+func Judge(answers []string, options []string) []string {
+	var res []string
+	for _, answer := range answers {
+		if letters := Letters(answer); len(letters) == 1 && lettersInRange(letters, len(options)) {
+			res = append(res, options[letters[0][0]-'A'])
+			continue
+		}
+		value, ok := JudgeValue(answer)
+		if !ok {
+			if option, matched := matchOption(answer, options); matched {
+				res = append(res, option)
+			} else {
+				res = append(res, answer)
+			}
+			continue
+		}
+		res = append(res, judgeOption(value, options))
+	}
+	return res
+}
+
+// judgeOption 找出表示对或错的选项，没有时返回“正确”或“错误”
+func judgeOption(value bool, options []string) string {
+	for _, option := range options {
+		if v, ok := JudgeValue(OptionText(option)); ok && v == value {
+			return option
+		}
+	}
+	if value {
+		return JudgeTrue
+	}
+	return JudgeFalse
+}
+
+// Blank 清理填空题单个空的答案，去掉“答案：”、序号、两端的引号和末尾标点
+func Blank(answer string) string {
+	answer = stripPrefix(answer)
+	answer = blankIndexReg.ReplaceAllString(answer, "")
+	answer = strings.Trim(answer, " \t\r\n\"'“”‘’")
+	return strings.TrimRightFunc(answer, func(r rune) bool {
+		return unicode.IsSpace(r) || strings.ContainsRune("。.，,；;、", r)
+	})
+}
+
+// Blanks 清理填空题答案，count为空的数量，只有一个答案而空有多个时按分号或序号拆开
+func Blanks(answers []string, count int) []string {
+	if len(answers) == 1 && count > 1 {
+		var parts []string
+		for _, part := range blankSepReg.Split(stripPrefix(answers[0]), -1) {
+			if strings.TrimSpace(part) != "" {
+				parts = append(parts, part)
+			}
+		}
+		if len(parts) == count {
+			answers = parts
+		}
+	}
+	res := make([]string, 0, len(answers))
+	for _, answer := range answers {
+		res = append(res, Blank(answer))
+	}
+	return res
+}
+
+// Short 清理简答题答案，只去掉“答案：”和两端空白
+func Short(answer string) string {
+	return strings.TrimSpace(stripPrefix(answer))
+}
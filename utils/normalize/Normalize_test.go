@@ -0,0 +1,142 @@
+package normalize
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestLetters(t *testing.T) {
+	cases := map[string][]string{
+		"AC":      {"A", "C"},
+		"A、C":     {"A", "C"},
+		"A, C, D": {"A", "C", "D"},
+		"答案：B":    {"B"},
+		"B。":      {"B"},
+		"北京":      nil,
+		"bad":     nil,
+	}
+	for input, want := range cases {
+		if got := Letters(input); !reflect.DeepEqual(got, want) {
+			t.Errorf("Letters(%q) = %q, want %q", input, got, want)
+		}
+	}
+}
+
+func TestChoice(t *testing.T) {
+	options := []string{"北京", "上海", "广州", "深圳"}
+	cases := []struct {
+		answers []string
+		want    []string
+	}{
+		{[]string{"AC"}, []string{"北京", "广州"}},
+		{[]string{"A、C"}, []string{"北京", "广州"}},
+		{[]string{"A", "C", "A"}, []string{"北京", "广州"}},
+		{[]string{"B. 上海"}, []string{"上海"}},
+		{[]string{"D．深圳市"}, []string{"深圳"}},
+		{[]string{"上海。"}, []string{"上海"}},
+		{[]string{"答案：D"}, []string{"深圳"}},
+		{[]string{"杭州"}, []string{"杭州"}},
+		{[]string{"E"}, []string{"E"}},
+	}
+	for _, c := range cases {
+		if got := Choice(c.answers, options); !reflect.DeepEqual(got, c.want) {
+			t.Errorf("Choice(%q) = %q, want %q", c.answers, got, c.want)
+		}
+	}
+}
+
+func TestChoiceYinghuaOptions(t *testing.T) {
+	options := []string{"A北京", "B上海"}
+	if got := Choice([]string{"上海"}, options); !reflect.DeepEqual(got, []string{"B上海"}) {
+		t.Errorf("Choice = %q, want [B上海]", got)
+	}
+	if got := Choice([]string{"A"}, options); !reflect.DeepEqual(got, []string{"A北京"}) {
+		t.Errorf("Choice = %q, want [A北京]", got)
+	}
+}
+
+func TestChoiceOptionLooksLikeLetters(t *testing.T) {
+	options := []string{"CAB", "BAD", "DAB", "ACE"}
+	if got := Choice([]string{"BAD"}, options); !reflect.DeepEqual(got, []string{"BAD"}) {
+		t.Errorf("Choice = %q, want [BAD]", got)
+	}
+}
+
+func TestJudgeValue(t *testing.T) {
+	for _, answer := range []string{"对", "正确", "√", "✓", "T", "true", "True", "是", "Yes", "正确。"} {
+		if value, ok := JudgeValue(answer); !ok || !value {
+			t.Errorf("JudgeValue(%q) = %v,%v, want true", answer, value, ok)
+		}
+	}
+	for _, answer := range []string{"错", "错误", "×", "✗", "F", "false", "否", "No", "不正确"} {
+		if value, ok := JudgeValue(answer); !ok || value {
+			t.Errorf("JudgeValue(%q) = %v,%v, want false", answer, value, ok)
+		}
+	}
+	if _, ok := JudgeValue("不确定"); ok {
+		t.Errorf("JudgeValue(不确定) should not be recognized")
+	}
+}
+
+func TestJudge(t *testing.T) {
+	cases := []struct {
+		answers []string
+		options []string
+		want    []string
+	}{
+		{[]string{"√"}, nil, []string{JudgeTrue}},
+		{[]string{"×"}, nil, []string{JudgeFalse}},
+		{[]string{"T"}, []string{"对", "错"}, []string{"对"}},
+		{[]string{"F"}, []string{"A正确", "B错误"}, []string{"B错误"}},
+		{[]string{"B"}, []string{"A. 对", "B. 错"}, []string{"B. 错"}},
+		{[]string{"说不清"}, nil, []string{"说不清"}},
+	}
+	for _, c := range cases {
+		if got := Judge(c.answers, c.options); !reflect.DeepEqual(got, c.want) {
+			t.Errorf("Judge(%q, %q) = %q, want %q", c.answers, c.options, got, c.want)
+		}
+	}
+}
+
+func TestBlank(t *testing.T) {
+	cases := map[string]string{
+		"(1) 光合作用。":   "光合作用",
+		"（2）叶绿体":      "叶绿体",
+		"第一空：细胞膜；":    "细胞膜",
+		"① 水":         "水",
+		"答案：\"二氧化碳\"": "二氧化碳",
+		"3.14":        "3.14",
+		"1. 3.14":     "3.14",
+		"1、牛顿":        "牛顿",
+	}
+	for input, want := range cases {
+		if got := Blank(input); got != want {
+			t.Errorf("Blank(%q) = %q, want %q", input, got, want)
+		}
+	}
+}
+
+func TestBlanks(t *testing.T) {
+	cases := []struct {
+		answers []string
+		count   int
+		want    []string
+	}{
+		{[]string{"H2O；O2"}, 2, []string{"H2O", "O2"}},
+		{[]string{"(1)H2O (2)O2"}, 2, []string{"H2O", "O2"}},
+		{[]string{"第一空：北京 第二空：上海"}, 2, []string{"北京", "上海"}},
+		{[]string{"H2O；O2"}, 3, []string{"H2O；O2"}},
+		{[]string{"(1) 北京", "(2) 上海。"}, 2, []string{"北京", "上海"}},
+	}
+	for _, c := range cases {
+		if got := Blanks(c.answers, c.count); !reflect.DeepEqual(got, c.want) {
+			t.Errorf("Blanks(%q, %d) = %q, want %q", c.answers, c.count, got, c.want)
+		}
+	}
+}
+
+func TestShort(t *testing.T) {
+	if got := Short("答案： 细胞是生命活动的基本单位。 "); got != "细胞是生命活动的基本单位。" {
+		t.Errorf("Short = %q", got)
+	}
+}
@@ -0,0 +1,95 @@
+package pacing
+
+import (
+	"math/rand"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Pacing 平台的学时提交节奏
+type Pacing struct {
+	Interval int //两次提交之间的基础间隔，单位秒
+	Jitter   int //随机抖动范围，单位秒
+	Step     int //每次提交推进的学时，单位秒
+	Gap      int //两个任务点之间的间隔，单位秒
+}
+
+// 各平台默认节奏，与原先写死的间隔保持一致
+var defaultPacing = map[string]Pacing{
+	"YINGHUA":   {Interval: 5, Step: 5, Gap: 8},
+	"CANGHUI":   {Interval: 5, Step: 5, Gap: 8},
+	"ENAEA":     {Interval: 25},
+	"CQIE":      {Interval: 3, Step: 3},
+	"WELEARN":   {Interval: 60, Step: 60},
+	"XUEXITONG": {Interval: 58, Step: 58, Gap: 10},
+	"KETANGX":   {},
+}
+
+var (
+	pacingMap = map[string]Pacing{} //用户配置过的平台节奏
+	pacingMut sync.RWMutex
+)
+
+// Set 设置平台节奏，为0的项沿用默认值
+func Set(platform string, p Pacing) {
+	platform = strings.ToUpper(platform)
+	def := defaultPacing[platform]
+	if p.Interval <= 0 {
+		p.Interval = def.Interval
+	}
+	if p.Jitter <= 0 {
+		p.Jitter = def.Jitter
+	}
+	if p.Step <= 0 {
+		p.Step = def.Step
+	}
+	if p.Gap <= 0 {
+		p.Gap = def.Gap
+	}
+	pacingMut.Lock()
+	defer pacingMut.Unlock()
+	pacingMap[platform] = p
+}
+
+// Get 获取平台节奏，未配置则返回默认值
+func Get(platform string) Pacing {
+	platform = strings.ToUpper(platform)
+	pacingMut.RLock()
+	defer pacingMut.RUnlock()
+	if p, ok := pacingMap[platform]; ok {
+		return p
+	}
+	return defaultPacing[platform]
+}
+
+// jitter 在base基础上加上±Jitter秒的随机抖动，最低为0
+func (p Pacing) jitter(base int) time.Duration {
+	d := time.Duration(base) * time.Second
+	if p.Jitter > 0 {
+		j := int64(p.Jitter) * int64(time.Second)
+		d += time.Duration(rand.Int63n(2*j+1) - j)
+	}
+	if d < 0 {
+		d = 0
+	}
+	return d
+}
+
+// NextInterval 获取下一次提交前需要等待的时间
+func (p Pacing) NextInterval() time.Duration {
+	return p.jitter(p.Interval)
+}
+
+// Wait 等待一个提交间隔
+func (p Pacing) Wait() {
+	time.Sleep(p.NextInterval())
+}
+
+// WaitGap 等待一个任务点间隔
+func (p Pacing) WaitGap() {
+	if p.Gap <= 0 {
+		return
+	}
+	time.Sleep(p.jitter(p.Gap))
+}
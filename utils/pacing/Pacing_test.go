@@ -0,0 +1,33 @@
+package pacing
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSetDefaults(t *testing.T) {
+	Set("xuexitong", Pacing{Interval: 30})
+	got := Get("XUEXITONG")
+	want := Pacing{Interval: 30, Step: 58, Gap: 10}
+	if got != want {
+		t.Errorf("Get = %+v, want %+v", got, want)
+	}
+	if got := Get("YINGHUA"); got.Gap != 8 {
+		t.Errorf("英华默认去红间隔应为8秒，得到%d", got.Gap)
+	}
+}
+
+func TestJitter(t *testing.T) {
+	p := Pacing{Interval: 10, Jitter: 3}
+	for i := 0; i < 200; i++ {
+		if d := p.NextInterval(); d < 7*time.Second || d > 13*time.Second {
+			t.Fatalf("NextInterval = %v，超出10±3秒", d)
+		}
+	}
+	if d := (Pacing{Interval: 1, Jitter: 5}).jitter(1); d < 0 {
+		t.Errorf("间隔不能为负数：%v", d)
+	}
+	if d := (Pacing{Interval: 10}).NextInterval(); d != 10*time.Second {
+		t.Errorf("没有抖动时应为固定间隔，得到%v", d)
+	}
+}
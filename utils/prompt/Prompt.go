@@ -0,0 +1,95 @@
+package prompt
+
+import (
+	"strings"
+	"sync"
+	"text/template"
+
+	"github.com/yatori-dev/yatori-go-core/que-core/aiq"
+)
+
+// 提示词种类
+const (
+	KindChoice = "choice" //单选、多选
+	KindJudge  = "judge"  //判断
+	KindFill   = "fill"   //填空
+	KindShort  = "short"  //简答
+	KindTerm   = "term"   //名词解释
+	KindEssay  = "essay"  //论述
+	KindBBS    = "bbs"    //讨论回复
+)
+
+// Data 模板中可以使用的字段，比如{{.Course}}、{{.Content}}
+type Data struct {
+	Platform string   //平台类型，比如XUEXITONG、YINGHUA
+	Account  string   //账号
+	Course   string   //课程名称
+	Title    string   //试卷或讨论名称
+	Type     string   //题型
+	Content  string   //题目内容
+	Options  []string //选项
+	Default  string   //内置提示词中的题目描述，可以在模板中直接引用后再追加要求
+}
+
+// Kind 题型对应的提示词种类，识别不了的题型返回空
+func Kind(qType string) string {
+	qType = strings.TrimSuffix(strings.TrimSpace(qType), "题")
+	switch qType {
+	case "单选", "多选":
+		return KindChoice
+	case "判断":
+		return KindJudge
+	case "填空":
+		return KindFill
+	case "简答":
+		return KindShort
+	case "名词解释":
+		return KindTerm
+	case "论述":
+		return KindEssay
+	}
+	return ""
+}
+
+var (
+	cacheMut sync.Mutex
+	cache    = map[string]*template.Template{}
+)
+
+// Render 渲染模板，同一个模板只解析一次
+func Render(text string, data Data) (string, error) {
+	cacheMut.Lock()
+	tmpl, ok := cache[text]
+	if !ok {
+		var err error
+		tmpl, err = template.New("prompt").Parse(text)
+		if err != nil {
+			cacheMut.Unlock()
+			return "", err
+		}
+		cache[text] = tmpl
+	}
+	cacheMut.Unlock()
+	var b strings.Builder
+	if err := tmpl.Execute(&b, data); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+// Apply 用模板渲染的内容替换内置提示词中的题目描述(最后一条用户消息)，内置的回答格式要求保持不变，
+// text为空时原样返回，data.Default会被填为原来的题目描述
+func Apply(messages aiq.AIChatMessages, text string, data Data) (aiq.AIChatMessages, error) {
+	if text == "" || len(messages.Messages) == 0 {
+		return messages, nil
+	}
+	last := len(messages.Messages) - 1
+	data.Default = messages.Messages[last].Content
+	content, err := Render(text, data)
+	if err != nil {
+		return messages, err
+	}
+	res := append([]aiq.Message(nil), messages.Messages[:last]...)
+	res = append(res, aiq.Message{Role: messages.Messages[last].Role, Content: content})
+	return aiq.AIChatMessages{Messages: res}, nil
+}
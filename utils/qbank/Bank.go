@@ -0,0 +1,318 @@
+package qbank
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	lg "github.com/yatori-dev/yatori-go-core/utils/log"
+	"github.com/yatori-dev/yatori-go-core/utils/qutils"
+)
+
+// BankPath 本地题库文件位置，所有账号共用同一份
+var BankPath = "./assets/bank/question_bank.json"
+
+// 答案来源，来源可信度越高越不会被覆盖
+const (
+	SourceAI       = "ai"       //AI回答
+	SourceExternal = "external" //外挂题库回答
+	SourceImport   = "import"   //手动导入
+	SourceVerified = "verified" //经过批改确认正确
+)
+
+var sourceRank = map[string]int{
+	SourceAI:       1,
+	SourceExternal: 2,
+	SourceImport:   3,
+	SourceVerified: 4,
+}
+
+// Entry 题库中的一道题
+type Entry struct {
+	Type    string    `json:"type"`              //题目类型，比如单选、多选、判断、填空、简答
+	Content string    `json:"content"`           //题目内容
+	Options []string  `json:"options,omitempty"` //选项（一般选择题才会有）
+	Answers []string  `json:"answers"`           //答案，选择题为选项内容，填空题按空的顺序排列
+	Source  string    `json:"source"`            //答案来源
+	Hits    int       `json:"hits"`              //命中次数
+	Updated time.Time `json:"updated"`           //最近一次更新时间
+}
+
+// FlushDelay 写入题库后延迟多久写回文件，期间的多次写入合并为一次
+var FlushDelay = 10 * time.Second
+
+var (
+	bankMut    sync.Mutex
+	loaded     bool      //是否已从文件读取
+	loadedMod  time.Time //读取时文件的修改时间
+	readOnly   bool      //只读模式，不写回文件，文件被其他进程修改后重新读取
+	entries    = map[string]*Entry{}
+	disabled   bool
+	dirty      bool        //内存中有未写回文件的变动
+	flushTimer *time.Timer //延迟写回的定时器，为空代表没有等待中的写回
+)
+
+// SetPath 设置题库文件位置，位置变化时先写回原文件再从新位置重新读取
+func SetPath(path string) {
+	if path == "" {
+		return
+	}
+	bankMut.Lock()
+	defer bankMut.Unlock()
+	if path == BankPath {
+		return
+	}
+	if err := flush(); err != nil {
+		lg.Print(lg.INFO, lg.BoldRed, "本地题库写入失败：", err.Error())
+	}
+	BankPath = path
+	entries, loaded, loadedMod = map[string]*Entry{}, false, time.Time{}
+}
+
+// SetReadOnly 开关只读模式，题库服务与刷课进程共用题库文件时使用，
+// 只读模式下不写入也不记录命中次数，避免用旧数据覆盖刷课进程写入的答案，文件变化后自动重新读取
+func SetReadOnly(enabled bool) {
+	bankMut.Lock()
+	defer bankMut.Unlock()
+	readOnly = enabled
+}
+
+// SetEnabled 开关本地题库，关闭后查询永远不命中，也不再写入
+func SetEnabled(enabled bool) {
+	bankMut.Lock()
+	defer bankMut.Unlock()
+	disabled = !enabled
+}
+
+// load 第一次使用时从文件读取题库，只读模式下文件修改时间变化时重新读取，调用前需持有锁
+func load() {
+	if loaded && !readOnly {
+		return
+	}
+	info, err := os.Stat(BankPath)
+	if err != nil {
+		loaded = true
+		return
+	}
+	if loaded && info.ModTime().Equal(loadedMod) {
+		return
+	}
+	loaded, loadedMod = true, info.ModTime()
+	content, err := os.ReadFile(BankPath)
+	if err != nil {
+		return
+	}
+	fresh := map[string]*Entry{}
+	if json.Unmarshal(content, &fresh) == nil {
+		entries = fresh
+	}
+}
+
+// markDirty 标记题库有变动，schedule为true时在FlushDelay后写回文件，调用前需持有锁
+func markDirty(schedule bool) {
+	if readOnly {
+		return
+	}
+	dirty = true
+	if schedule && flushTimer == nil {
+		flushTimer = time.AfterFunc(FlushDelay, func() {
+			if err := Flush(); err != nil {
+				lg.Print(lg.INFO, lg.BoldRed, "本地题库写入失败：", err.Error())
+			}
+		})
+	}
+}
+
+// flush 有变动时写回文件，调用前需持有锁
+func flush() error {
+	if flushTimer != nil {
+		flushTimer.Stop()
+		flushTimer = nil
+	}
+	if !dirty {
+		return nil
+	}
+	if err := save(); err != nil {
+		return err
+	}
+	dirty = false
+	return nil
+}
+
+// save 将题库写回文件，先写临时文件再替换，避免中途退出把题库写坏
+func save() error {
+	data, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(filepath.Dir(BankPath), 0755); err != nil {
+		return err
+	}
+	tmp := BankPath + ".tmp"
+	if err = os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, BankPath)
+}
+
+var tagReg = regexp.MustCompile(`<[^>]*>`)
+
+// Normalize 题目文本归一化：去掉html标签、空白和标点，全角转半角并统一小写
+func Normalize(text string) string {
+	text = tagReg.ReplaceAllString(text, "")
+	text = strings.ReplaceAll(text, "&nbsp;", "")
+	var b strings.Builder
+	for _, r := range text {
+		if r == '　' {
+			continue
+		}
+		if r >= '！' && r <= '～' { //全角转半角
+			r -= 0xfee0
+		}
+		if unicode.IsSpace(r) || unicode.IsPunct(r) || unicode.IsSymbol(r) {
+			continue
+		}
+		b.WriteRune(unicode.ToLower(r))
+	}
+	return b.String()
+}
+
+var optionPrefixReg = regexp.MustCompile(`^\s*[A-Za-z][.、．:：)）]\s*`)
+
+// normalizeOption 选项归一化，额外去掉开头的选项字母，比如“A、正确”和“正确”视为同一个选项
+func normalizeOption(option string) string {
+	return Normalize(optionPrefixReg.ReplaceAllString(option, ""))
+}
+
+// NormalizeType 统一各平台的题型叫法，比如“单选题”和“单选”视为同一种
+func NormalizeType(qType string) string {
+	return strings.TrimSuffix(strings.TrimSpace(qType), "题")
+}
+
+// Key 由题型、题目和选项生成题库键，选项顺序不影响结果
+func Key(qType, content string, options []string) string {
+	normOptions := make([]string, 0, len(options))
+	for _, option := range options {
+		normOptions = append(normOptions, normalizeOption(option))
+	}
+	sort.Strings(normOptions)
+	sum := md5.Sum([]byte(NormalizeType(qType) + "|" + Normalize(content) + "|" + strings.Join(normOptions, "|")))
+	return hex.EncodeToString(sum[:])
+}
+
+// Lookup 查询题库，命中时返回答案
+func Lookup(qType, content string, options []string) (Entry, bool) {
+	bankMut.Lock()
+	defer bankMut.Unlock()
+	if disabled {
+		return Entry{}, false
+	}
+	load()
+	entry, ok := entries[Key(qType, content, options)]
+	if !ok || len(entry.Answers) == 0 {
+		return Entry{}, false
+	}
+	if !readOnly {
+		entry.Hits++
+		markDirty(false) //命中次数不急着写回，随下一次写入或Flush一起保存
+	}
+	return *entry, true
+}
+
+// Verified 题库中这道题是否有批改确认过的答案，不计入命中次数
+func Verified(qType, content string, options []string) bool {
+	bankMut.Lock()
+	defer bankMut.Unlock()
+	if disabled {
+		return false
+	}
+	load()
+	entry, ok := entries[Key(qType, content, options)]
+	return ok && entry.Source == SourceVerified && len(entry.Answers) > 0
+}
+
+// Store 写入一道题，已有的题只会被同等或更可信来源的答案覆盖，写入内存后延迟批量写回文件
+func Store(entry Entry) error {
+	if len(entry.Answers) == 0 || strings.TrimSpace(entry.Content) == "" {
+		return nil
+	}
+	bankMut.Lock()
+	defer bankMut.Unlock()
+	if disabled || readOnly {
+		return nil
+	}
+	load()
+	key := Key(entry.Type, entry.Content, entry.Options)
+	if old, ok := entries[key]; ok {
+		if sourceRank[entry.Source] < sourceRank[old.Source] {
+			return nil
+		}
+		entry.Hits = old.Hits
+	}
+	entry.Updated = time.Now()
+	entries[key] = &entry
+	markDirty(true)
+	return nil
+}
+
+// Flush 立即把内存中的变动写回文件，程序结束前需调用
+func Flush() error {
+	bankMut.Lock()
+	defer bankMut.Unlock()
+	if disabled {
+		return nil
+	}
+	return flush()
+}
+
+// MatchOptions 将题库中的选项答案对应到本次题目的选项上，选项被打乱顺序时也能选对
+func MatchOptions(answers []string, options []string) []string {
+	var res []string
+	for _, answer := range answers {
+		matched := answer
+		for _, option := range options {
+			if normalizeOption(option) == normalizeOption(answer) {
+				matched = option
+				break
+			}
+		}
+		res = append(res, matched)
+	}
+	return res
+}
+
+var letterReg = regexp.MustCompile(`^[A-Za-z]$`)
+
+// OptionScore 答案与选项的匹配程度，取每个答案与最接近选项的相似度中的最低值，答案为选项字母时视为完全匹配，没有选项时返回1
+func OptionScore(answers []string, options []string) float64 {
+	if len(options) == 0 {
+		return 1
+	}
+	if len(answers) == 0 {
+		return 0
+	}
+	score := 1.0
+	for _, answer := range answers {
+		if letter := strings.ToUpper(strings.TrimSpace(answer)); letterReg.MatchString(letter) && int(letter[0]-'A') < len(options) {
+			continue
+		}
+		best := 0.0
+		for _, option := range options {
+			if similarity := qutils.Similarity(normalizeOption(answer), normalizeOption(option)); similarity > best {
+				best = similarity
+			}
+		}
+		if best < score {
+			score = best
+		}
+	}
+	return score
+}
@@ -0,0 +1,139 @@
+package qbank
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// useTempBank 使用临时题库文件，测试结束后恢复
+func useTempBank(t *testing.T) string {
+	t.Helper()
+	old := BankPath
+	path := filepath.Join(t.TempDir(), "bank.json")
+	SetPath(path)
+	t.Cleanup(func() {
+		SetPath(old)
+	})
+	return path
+}
+
+func TestKey(t *testing.T) {
+	base := Key("单选题", "中国的首都是？", []string{"A. 北京", "B. 上海"})
+	same := []string{
+		Key("单选", "中国的首都是？", []string{"A. 北京", "B. 上海"}),
+		Key("单选题", "<p>中国的首都是?</p>", []string{"上海", "北京"}),
+		Key("单选题", " 中国的首都是 ", []string{"B、上海", "A、北京"}),
+	}
+	for i, key := range same {
+		if key != base {
+			t.Errorf("same[%d] 应与原题键相同", i)
+		}
+	}
+	if Key("多选题", "中国的首都是？", []string{"A. 北京", "B. 上海"}) == base {
+		t.Error("题型不同时键应不同")
+	}
+	if Key("单选题", "中国的首都是？", []string{"A. 北京", "B. 广州"}) == base {
+		t.Error("选项不同时键应不同")
+	}
+}
+
+func TestStoreLookup(t *testing.T) {
+	useTempBank(t)
+	options := []string{"北京", "上海"}
+	if _, ok := Lookup("单选", "首都", options); ok {
+		t.Fatal("空题库不应命中")
+	}
+	Store(Entry{Type: "单选", Content: "首都", Options: options, Answers: []string{"北京"}, Source: SourceImport})
+	//可信度更低的来源不会覆盖
+	Store(Entry{Type: "单选", Content: "首都", Options: options, Answers: []string{"上海"}, Source: SourceAI})
+	entry, ok := Lookup("单选", "首都", []string{"上海", "北京"})
+	if !ok || entry.Answers[0] != "北京" || entry.Source != SourceImport {
+		t.Fatalf("Lookup = %+v %v", entry, ok)
+	}
+	Store(Entry{Type: "单选", Content: "首都", Options: options, Answers: []string{"上海"}, Source: SourceVerified})
+	if entry, _ = Lookup("单选", "首都", options); entry.Answers[0] != "上海" || entry.Hits != 2 {
+		t.Errorf("更可信来源应覆盖并保留命中次数，得到%+v", entry)
+	}
+	if !Verified("单选", "首都", options) {
+		t.Error("批改确认的答案应为Verified")
+	}
+	//没有答案或题目为空的不写入
+	Store(Entry{Type: "单选", Content: "空答案", Source: SourceAI})
+	if _, ok = Lookup("单选", "空答案", nil); ok {
+		t.Error("没有答案的题不应写入")
+	}
+}
+
+func TestFlushAndSetPath(t *testing.T) {
+	path := useTempBank(t)
+	Store(Entry{Type: "判断", Content: "地球是圆的", Answers: []string{"正确"}, Source: SourceAI})
+	if _, err := os.Stat(path); err == nil {
+		t.Fatal("写入应延迟批量写回，不应立即写文件")
+	}
+	if err := Flush(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Fatalf("Flush后应写回文件：%v", err)
+	}
+
+	//切换到新位置后重新读取，新位置为空
+	other := filepath.Join(t.TempDir(), "other.json")
+	SetPath(other)
+	if _, ok := Lookup("判断", "地球是圆的", nil); ok {
+		t.Error("切换题库位置后不应命中原题库的题")
+	}
+	SetPath(path)
+	if _, ok := Lookup("判断", "地球是圆的", nil); !ok {
+		t.Error("切换回原位置后应重新读取原题库")
+	}
+}
+
+func TestImportDedup(t *testing.T) {
+	useTempBank(t)
+	list := []Entry{
+		{Type: "单选题", Content: "中国的首都是？", Options: []string{"北京", "上海"}, Answers: []string{"北京"}},
+		{Type: "单选", Content: "中国的首都是", Options: []string{"上海", "北京"}, Answers: []string{"北京"}}, //题型、标点、选项顺序不同的同一道题
+		{Type: "判断", Content: "水在0度结冰", Answers: []string{"正确"}},
+		{Type: "判断", Content: "没有答案"},
+	}
+	added, duplicated, err := Import(list, SourceImport)
+	if err != nil || added != 2 || duplicated != 1 {
+		t.Errorf("Import = %d %d %v, want 2 1 nil", added, duplicated, err)
+	}
+	if got := len(All()); got != 2 {
+		t.Errorf("题库中应有2道题，得到%d", got)
+	}
+}
+
+func TestReadOnlyReload(t *testing.T) {
+	path := useTempBank(t)
+	Store(Entry{Type: "单选", Content: "中国的首都是", Options: []string{"北京", "上海"}, Answers: []string{"北京"}, Source: SourceAI})
+	if err := Flush(); err != nil {
+		t.Fatal(err)
+	}
+	SetReadOnly(true)
+	defer SetReadOnly(false)
+	if _, ok := Lookup("单选", "中国的首都是", []string{"北京", "上海"}); !ok {
+		t.Fatal("只读模式下应能查询到已有的题")
+	}
+	//模拟刷课进程写入了新题
+	content := `{"` + Key("判断", "地球是圆的", nil) + `":{"type":"判断","content":"地球是圆的","answers":["正确"],"source":"verified"}}`
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	future := time.Now().Add(time.Minute)
+	os.Chtimes(path, future, future)
+	if _, ok := Lookup("判断", "地球是圆的", nil); !ok {
+		t.Fatal("文件变化后应重新读取")
+	}
+	//只读模式不写入也不写回
+	Store(Entry{Type: "填空", Content: "只读时写入", Answers: []string{"x"}, Source: SourceImport})
+	Flush()
+	data, _ := os.ReadFile(path)
+	if string(data) != content {
+		t.Fatalf("只读模式下题库文件被改写：%s", data)
+	}
+}
@@ -0,0 +1,49 @@
+package qbank
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/yatori-dev/yatori-go-core/que-core/qentity"
+)
+
+// Replier 本地题库服务的答复者名称
+const Replier = "yatori-local-bank"
+
+// Count 题库中题目数量
+func Count() int {
+	bankMut.Lock()
+	defer bankMut.Unlock()
+	load()
+	return len(entries)
+}
+
+// Handler 外挂题库接口，请求与返回格式同apiQueSetting的对接规范，
+// 请求体为题目(type、content、options)，返回题目及答案，code为200代表找到答案，404代表未找到
+func Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		var question qentity.Question
+		if err := json.NewDecoder(r.Body).Decode(&question); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(qentity.ResultQuestion{Replier: Replier, Msg: "请求格式错误：" + err.Error(), Code: http.StatusBadRequest})
+			return
+		}
+		res := qentity.ResultQuestion{Question: question, Replier: Replier}
+		if entry, ok := Lookup(question.Type, question.Content, question.Options); ok {
+			res.Answers = MatchOptions(entry.Answers, question.Options)
+			res.Msg = "查询成功"
+			res.Code = http.StatusOK
+		} else {
+			res.Answers = []string{}
+			res.Msg = "未找到答案"
+			res.Code = http.StatusNotFound
+		}
+		json.NewEncoder(w).Encode(res)
+	})
+}
+
+// Serve 在指定地址启动本地题库服务，会一直阻塞
+func Serve(addr string) error {
+	return http.ListenAndServe(addr, Handler())
+}
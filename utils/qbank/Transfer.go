@@ -0,0 +1,331 @@
+package qbank
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/yatori-dev/yatori-go-core/utils/qutils"
+)
+
+// SimilarThreshold 导入时题目相似度不低于该值且选项一致则视为同一道题
+var SimilarThreshold = 0.9
+
+// 表格(CSV、XLSX)的表头，导出时使用，导入时也支持英文表头
+var tableHeader = []string{"类型", "题目", "选项", "答案"}
+
+var headerAlias = map[string]int{
+	"类型": 0, "题型": 0, "type": 0,
+	"题目": 1, "内容": 1, "content": 1, "question": 1,
+	"选项": 2, "options": 2,
+	"答案": 3, "answers": 3, "answer": 3,
+}
+
+// 选项、答案在一个单元格内时的分隔符
+const listSep = "|"
+
+// ReadFile 按文件后缀读取题目，支持json、csv、xlsx、txt
+func ReadFile(path string) ([]Entry, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		var list []Entry
+		if err = json.Unmarshal(content, &list); err != nil {
+			return nil, err
+		}
+		return list, nil
+	case ".csv":
+		file, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		defer file.Close()
+		reader := csv.NewReader(file)
+		reader.FieldsPerRecord = -1
+		rows, err := reader.ReadAll()
+		if err != nil {
+			return nil, err
+		}
+		return parseTable(rows), nil
+	case ".xlsx":
+		rows, err := readXlsx(path)
+		if err != nil {
+			return nil, err
+		}
+		return parseTable(rows), nil
+	case ".txt":
+		file, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		defer file.Close()
+		return parseText(file)
+	}
+	return nil, errors.New("不支持的题库文件格式：" + filepath.Ext(path))
+}
+
+// parseTable 解析表格，第一行为表头时按表头对应列，否则按 类型、题目、选项、答案 的顺序
+func parseTable(rows [][]string) []Entry {
+	columns := []int{0, 1, 2, 3}
+	if len(rows) > 0 {
+		header := map[int]int{}
+		for i, cell := range rows[0] {
+			if field, ok := headerAlias[strings.ToLower(strings.TrimSpace(strings.TrimPrefix(cell, "\ufeff")))]; ok {
+				header[field] = i
+			}
+		}
+		if _, ok := header[1]; ok {
+			for field := range columns {
+				columns[field] = -1
+				if i, ok := header[field]; ok {
+					columns[field] = i
+				}
+			}
+			rows = rows[1:]
+		}
+	}
+	cell := func(row []string, field int) string {
+		if columns[field] < 0 || columns[field] >= len(row) {
+			return ""
+		}
+		return strings.TrimSpace(row[columns[field]])
+	}
+	var list []Entry
+	for _, row := range rows {
+		entry := Entry{Type: cell(row, 0), Content: cell(row, 1), Options: splitList(cell(row, 2)), Answers: splitList(cell(row, 3))}
+		entry.Answers = resolveLetters(entry.Answers, entry.Options)
+		if entry.Content != "" {
+			list = append(list, entry)
+		}
+	}
+	return list
+}
+
+var (
+	textTypeReg   = regexp.MustCompile(`^[\[【(（]([^\]】)）]+)[\]】)）]\s*`)
+	textOptionReg = regexp.MustCompile(`^[A-Za-z][.、．:：)）]\s*`)
+	textAnswerReg = regexp.MustCompile(`^(答案|正确答案|answer)\s*[:：]\s*`)
+)
+
+// parseText 解析纯文本题库，题目之间用空行分隔，格式如下：
+//
+//	【单选】题目内容
+//	A. 选项一
+//	B. 选项二
+//	答案：A
+func parseText(file *os.File) ([]Entry, error) {
+	var list []Entry
+	var entry Entry
+	flush := func() {
+		entry.Answers = resolveLetters(entry.Answers, entry.Options)
+		if strings.TrimSpace(entry.Content) != "" {
+			list = append(list, entry)
+		}
+		entry = Entry{}
+	}
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 1024*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(strings.TrimPrefix(scanner.Text(), "\ufeff"))
+		switch {
+		case line == "":
+			flush()
+		case textAnswerReg.MatchString(line):
+			entry.Answers = splitList(textAnswerReg.ReplaceAllString(line, ""))
+		case entry.Content != "" && textOptionReg.MatchString(line):
+			entry.Options = append(entry.Options, line)
+		case entry.Content == "":
+			if match := textTypeReg.FindStringSubmatch(line); match != nil {
+				entry.Type = match[1]
+				line = strings.TrimSpace(line[len(match[0]):])
+			}
+			entry.Content = line
+		default: //题目内容有多行
+			entry.Content += "\n" + line
+		}
+	}
+	flush()
+	return list, scanner.Err()
+}
+
+// splitList 拆分单元格里的多个选项或答案
+func splitList(text string) []string {
+	var list []string
+	for _, item := range strings.FieldsFunc(text, func(r rune) bool { return r == '|' || r == '\n' || r == '；' || r == ';' }) {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
+
+var letterAnswerReg = regexp.MustCompile(`^[A-Za-z]+$`)
+
+// resolveLetters 答案写的是选项字母(比如A或ABD)时转为对应的选项内容
+func resolveLetters(answers []string, options []string) []string {
+	if len(options) == 0 || len(answers) != 1 || !letterAnswerReg.MatchString(answers[0]) {
+		return answers
+	}
+	var res []string
+	for _, letter := range strings.ToUpper(answers[0]) {
+		index := int(letter - 'A')
+		if index >= len(options) {
+			return answers
+		}
+		res = append(res, options[index])
+	}
+	return res
+}
+
+// sameOptions 两道题的选项是否一致，不区分顺序
+func sameOptions(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	normA, normB := make([]string, len(a)), make([]string, len(b))
+	for i := range a {
+		normA[i], normB[i] = normalizeOption(a[i]), normalizeOption(b[i])
+	}
+	sort.Strings(normA)
+	sort.Strings(normB)
+	return strings.Join(normA, "|") == strings.Join(normB, "|")
+}
+
+// findSimilar 在题库中查找同题型、选项一致且题目足够相似的题，返回其键
+func findSimilar(entry Entry) (string, bool) {
+	qType := NormalizeType(entry.Type)
+	content := Normalize(entry.Content)
+	var keys, contents []string
+	for key, old := range entries {
+		if NormalizeType(old.Type) != qType || !sameOptions(old.Options, entry.Options) {
+			continue
+		}
+		oldContent := Normalize(old.Content)
+		//长度相差太多的不可能相似，跳过以减少计算量
+		if diff := len(oldContent) - len(content); diff > len(content)/2 || -diff > len(content)/2 {
+			continue
+		}
+		keys = append(keys, key)
+		contents = append(contents, oldContent)
+	}
+	if len(contents) == 0 {
+		return "", false
+	}
+	best := qutils.SimilarityArrayAndSort(content, contents)[0]
+	if best.Score < SimilarThreshold {
+		return "", false
+	}
+	return keys[best.Index], true
+}
+
+// Import 导入题目到本地题库，相同或相似的题只保留一份，返回新增和重复的数量
+func Import(list []Entry, source string) (added, duplicated int, err error) {
+	bankMut.Lock()
+	defer bankMut.Unlock()
+	load()
+	for _, entry := range list {
+		if len(entry.Answers) == 0 || strings.TrimSpace(entry.Content) == "" {
+			continue
+		}
+		entry.Source = source
+		entry.Updated = time.Now()
+		key := Key(entry.Type, entry.Content, entry.Options)
+		old, ok := entries[key]
+		if !ok {
+			if similarKey, found := findSimilar(entry); found {
+				key, old, ok = similarKey, entries[similarKey], true
+			}
+		}
+		if ok {
+			duplicated++
+			//重复的题以同等或更可信的来源为准，题目和选项保留题库中原有的写法
+			if sourceRank[entry.Source] >= sourceRank[old.Source] {
+				entry.Type, entry.Content, entry.Options, entry.Hits = old.Type, old.Content, old.Options, old.Hits
+				entries[key] = &entry
+			}
+			continue
+		}
+		entries[key] = &entry
+		added++
+	}
+	dirty = true //导入是一次性操作，直接写回文件
+	return added, duplicated, flush()
+}
+
+// All 按题型和题目排序返回题库中的所有题
+func All() []Entry {
+	bankMut.Lock()
+	defer bankMut.Unlock()
+	load()
+	list := make([]Entry, 0, len(entries))
+	for _, entry := range entries {
+		list = append(list, *entry)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Type != list[j].Type {
+			return list[i].Type < list[j].Type
+		}
+		return list[i].Content < list[j].Content
+	})
+	return list
+}
+
+// Export 按文件后缀导出题库，支持json、csv、xlsx、txt
+func Export(path string) (int, error) {
+	list := All()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return 0, err
+	}
+	var rows [][]string
+	rows = append(rows, tableHeader)
+	for _, entry := range list {
+		rows = append(rows, []string{entry.Type, entry.Content, strings.Join(entry.Options, listSep), strings.Join(entry.Answers, listSep)})
+	}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		data, err := json.MarshalIndent(list, "", "  ")
+		if err != nil {
+			return 0, err
+		}
+		return len(list), os.WriteFile(path, data, 0644)
+	case ".csv":
+		file, err := os.Create(path)
+		if err != nil {
+			return 0, err
+		}
+		defer file.Close()
+		file.WriteString("\ufeff") //带BOM，Excel直接打开不乱码
+		writer := csv.NewWriter(file)
+		writer.WriteAll(rows)
+		return len(list), writer.Error()
+	case ".xlsx":
+		return len(list), writeXlsx(path, rows)
+	case ".txt":
+		var b strings.Builder
+		for _, entry := range list {
+			if entry.Type != "" {
+				b.WriteString("【" + entry.Type + "】")
+			}
+			b.WriteString(entry.Content + "\n")
+			for i, option := range entry.Options {
+				if !textOptionReg.MatchString(option) { //没有选项字母的补上，重新导入时才能识别为选项
+					option = columnName(i) + ". " + option
+				}
+				b.WriteString(option + "\n")
+			}
+			b.WriteString("答案：" + strings.Join(entry.Answers, listSep) + "\n\n")
+		}
+		return len(list), os.WriteFile(path, []byte(b.String()), 0644)
+	}
+	return 0, errors.New("不支持的题库文件格式：" + filepath.Ext(path))
+}
@@ -0,0 +1,130 @@
+package result
+
+import (
+	"errors"
+	"strings"
+	"time"
+	"yatori-go-console/utils/retry"
+)
+
+// Kind 平台返回错误的分类
+type Kind int
+
+const (
+	Unknown        Kind = iota //未知错误
+	SessionExpired             //登录失效
+	Locked                     //未到解锁时间
+	RateLimited                //请求过于频繁
+	Detected                   //被平台检测到异常，比如并行播放、风控
+	NotStarted                 //课程或考试还未开始
+	Closed                     //课程、作业或考试已经结束，不会再开放
+	ServerError                //平台服务器临时错误，比如5xx
+)
+
+var kindNames = map[Kind]string{
+	Unknown:        "未知错误",
+	SessionExpired: "登录失效",
+	Locked:         "未到解锁时间",
+	RateLimited:    "请求过于频繁",
+	Detected:       "触发平台检测",
+	NotStarted:     "还未开始",
+	Closed:         "已结束",
+	ServerError:    "服务器错误",
+}
+
+func (k Kind) String() string {
+	return kindNames[k]
+}
+
+// Error 分类后的错误
+type Error struct {
+	Kind  Kind
+	Until time.Time //Kind为Locked时的解锁时间，解析不到则为零值
+	Msg   string    //平台返回的原始提示
+}
+
+func (e *Error) Error() string {
+	if e.Kind == Locked && !e.Until.IsZero() {
+		return "[" + e.Kind.String() + "] " + e.Until.Format("2006-01-02 15:04:05") + "解锁：" + e.Msg
+	}
+	return "[" + e.Kind.String() + "] " + e.Msg
+}
+
+// New 创建分类错误
+func New(kind Kind, msg string) *Error {
+	return &Error{Kind: kind, Msg: msg}
+}
+
+// NewLocked 创建带解锁时间的错误
+func NewLocked(until time.Time, msg string) *Error {
+	return &Error{Kind: Locked, Until: until, Msg: msg}
+}
+
+// KindOf 获取错误分类，不是分类错误则为Unknown
+func KindOf(err error) Kind {
+	var e *Error
+	if errors.As(err, &e) {
+		return e.Kind
+	}
+	return Unknown
+}
+
+// Is 判断错误是否属于某个分类
+func Is(err error, kind Kind) bool {
+	return err != nil && KindOf(err) == kind
+}
+
+// LockedUntil 获取未解锁错误的解锁时间
+func LockedUntil(err error) (time.Time, bool) {
+	var e *Error
+	if errors.As(err, &e) && e.Kind == Locked && !e.Until.IsZero() {
+		return e.Until, true
+	}
+	return time.Time{}, false
+}
+
+// Rule 平台提示关键字与分类的对应规则
+type Rule struct {
+	Kind     Kind
+	Keywords []string
+}
+
+// Match 按规则表对平台提示进行分类，都不匹配则为Unknown
+func Match(msg string, rules []Rule) Kind {
+	for _, rule := range rules {
+		for _, keyword := range rule.Keywords {
+			if strings.Contains(msg, keyword) {
+				return rule.Kind
+			}
+		}
+	}
+	return Unknown
+}
+
+// Classify 按规则表将普通错误转为分类错误，已经是分类错误的原样返回
+func Classify(err error, rules []Rule) error {
+	if err == nil {
+		return nil
+	}
+	var e *Error
+	if errors.As(err, &e) {
+		return err
+	}
+	return New(Match(err.Error(), rules), err.Error())
+}
+
+// Classifier 根据规则表生成重试用的错误分类器
+func Classifier(rules []Rule) retry.Classifier {
+	return func(err error) retry.Class {
+		return RetryClass(Classify(err, rules))
+	}
+}
+
+// RetryClass 分类错误对应的重试类别，登录失效、限流、服务器错误和未知错误可以重试，其余重试也没用
+func RetryClass(err error) retry.Class {
+	switch KindOf(err) {
+	case Locked, Detected, NotStarted, Closed:
+		return retry.Permanent
+	}
+	return retry.Retryable
+}
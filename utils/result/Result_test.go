@@ -0,0 +1,74 @@
+package result
+
+import (
+	"errors"
+	"testing"
+	"time"
+	"yatori-go-console/utils/retry"
+)
+
+var testRules = []Rule{
+	{Kind: SessionExpired, Keywords: []string{"请重新登录"}},
+	{Kind: ServerError, Keywords: []string{"status code: 500"}},
+	{Kind: Closed, Keywords: []string{"已结束"}},
+	{Kind: NotStarted, Keywords: []string{"未开始"}},
+}
+
+func TestMatch(t *testing.T) {
+	cases := map[string]Kind{
+		"请重新登录":                       SessionExpired,
+		"unexpected status code: 500": ServerError,
+		"考试已结束":                       Closed,
+		"课程还未开始":                      NotStarted,
+		"其他错误":                        Unknown,
+	}
+	for msg, want := range cases {
+		if got := Match(msg, testRules); got != want {
+			t.Errorf("Match(%q) = %v, want %v", msg, got, want)
+		}
+	}
+}
+
+func TestClassify(t *testing.T) {
+	if Classify(nil, testRules) != nil {
+		t.Error("nil错误应原样返回")
+	}
+	locked := NewLocked(time.Now(), "未到解锁时间")
+	if got := Classify(locked, testRules); got != error(locked) {
+		t.Error("已经是分类错误的应原样返回")
+	}
+	if got := Classify(errors.New("考试已结束"), testRules); !Is(got, Closed) {
+		t.Errorf("Classify = %v, want Closed", got)
+	}
+}
+
+func TestRetryClass(t *testing.T) {
+	cases := map[Kind]retry.Class{
+		Unknown:        retry.Retryable,
+		SessionExpired: retry.Retryable,
+		RateLimited:    retry.Retryable,
+		ServerError:    retry.Retryable,
+		Locked:         retry.Permanent,
+		Detected:       retry.Permanent,
+		NotStarted:     retry.Permanent,
+		Closed:         retry.Permanent,
+	}
+	for kind, want := range cases {
+		if got := RetryClass(New(kind, "x")); got != want {
+			t.Errorf("RetryClass(%v) = %v, want %v", kind, got, want)
+		}
+	}
+}
+
+func TestLockedUntil(t *testing.T) {
+	until := time.Date(2024, 11, 14, 12, 0, 0, 0, time.Local)
+	if got, ok := LockedUntil(NewLocked(until, "x")); !ok || !got.Equal(until) {
+		t.Errorf("LockedUntil = %v %v", got, ok)
+	}
+	if _, ok := LockedUntil(NewLocked(time.Time{}, "x")); ok {
+		t.Error("没有解锁时间时应返回false")
+	}
+	if _, ok := LockedUntil(New(NotStarted, "x")); ok {
+		t.Error("非Locked错误应返回false")
+	}
+}
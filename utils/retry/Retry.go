@@ -0,0 +1,204 @@
+package retry
+
+import (
+	"errors"
+	"math/rand"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	lg "github.com/yatori-dev/yatori-go-core/utils/log"
+)
+
+// Policy 重试策略
+type Policy struct {
+	MaxAttempts int           //最大尝试次数（包含第一次），小于等于0代表不限次数
+	BaseDelay   time.Duration //第一次重试前的等待时间，之后每次翻倍
+	MaxDelay    time.Duration //单次等待时间上限
+	Jitter      float64       //随机抖动比例，0.2代表在等待时间基础上±20%
+	Classifier  Classifier    //错误分类器，为空则只认Stop标记的永久错误
+}
+
+// DefaultPolicy 默认重试策略，第一次等待与原先写死的10s保持一致
+var DefaultPolicy = Policy{MaxAttempts: 6, BaseDelay: 10 * time.Second, MaxDelay: 5 * time.Minute, Jitter: 0.2}
+
+var (
+	policyMap     = map[string]Policy{}     //用户配置过的平台重试策略
+	classifierMap = map[string]Classifier{} //各平台注册的错误分类器
+	policyMut     sync.RWMutex
+)
+
+// Set 设置平台重试策略，为0的项沿用默认值
+func Set(platform string, p Policy) {
+	if p.MaxAttempts == 0 {
+		p.MaxAttempts = DefaultPolicy.MaxAttempts
+	}
+	if p.BaseDelay <= 0 {
+		p.BaseDelay = DefaultPolicy.BaseDelay
+	}
+	if p.MaxDelay <= 0 {
+		p.MaxDelay = DefaultPolicy.MaxDelay
+	}
+	if p.MaxDelay < p.BaseDelay {
+		p.MaxDelay = p.BaseDelay
+	}
+	if p.Jitter <= 0 {
+		p.Jitter = DefaultPolicy.Jitter
+	}
+	policyMut.Lock()
+	defer policyMut.Unlock()
+	policyMap[strings.ToUpper(platform)] = p
+}
+
+// RegisterClassifier 注册平台的错误分类器，用于识别该平台重试也没用的业务错误
+func RegisterClassifier(platform string, classifier Classifier) {
+	policyMut.Lock()
+	defer policyMut.Unlock()
+	classifierMap[strings.ToUpper(platform)] = classifier
+}
+
+// Get 获取平台重试策略，未配置则返回默认策略
+func Get(platform string) Policy {
+	policyMut.RLock()
+	defer policyMut.RUnlock()
+	p, ok := policyMap[strings.ToUpper(platform)]
+	if !ok {
+		p = DefaultPolicy
+	}
+	p.Classifier = classifierMap[strings.ToUpper(platform)]
+	return p
+}
+
+// Delay 获取第attempt次失败后的等待时间（attempt从1开始）
+func (p Policy) Delay(attempt int) time.Duration {
+	d := p.BaseDelay
+	for i := 1; i < attempt && d < p.MaxDelay; i++ {
+		d *= 2
+	}
+	if d > p.MaxDelay {
+		d = p.MaxDelay
+	}
+	if p.Jitter > 0 {
+		d += time.Duration((rand.Float64()*2 - 1) * p.Jitter * float64(d))
+	}
+	if d < 0 {
+		d = 0
+	}
+	return d
+}
+
+// ------------------------------------------------------------------错误分类
+
+// Class 错误分类
+type Class int
+
+const (
+	Retryable Class = iota //临时错误，可以重试
+	Permanent              //永久错误，重试也没用
+)
+
+// Classifier 错误分类器
+type Classifier func(err error) Class
+
+// ErrExhausted 重试次数耗尽
+var ErrExhausted = errors.New("重试次数已用完")
+
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string { return e.err.Error() }
+func (e *permanentError) Unwrap() error { return e.err }
+
+// Stop 将错误标记为永久错误，重试逻辑遇到后会立即停止
+func Stop(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &permanentError{err: err}
+}
+
+// Classify 判断错误是否值得重试，Stop标记的错误始终为永久错误，其余交给分类器判断
+func (p Policy) Classify(err error) Class {
+	var pe *permanentError
+	if errors.As(err, &pe) {
+		return Permanent
+	}
+	if p.Classifier != nil {
+		return p.Classifier(err)
+	}
+	return Retryable
+}
+
+// ------------------------------------------------------------------重试执行
+
+// Backoff 用于在已有的提交循环中计数失败次数并按策略等待
+type Backoff struct {
+	Policy  Policy
+	Attempt int //连续失败次数
+}
+
+// NewBackoff 按平台策略创建Backoff
+func NewBackoff(platform string) *Backoff {
+	return &Backoff{Policy: Get(platform)}
+}
+
+// Next 记录一次失败并返回需要等待的时间，返回false代表不应再重试（永久错误或次数耗尽）
+func (b *Backoff) Next(err error) (time.Duration, bool) {
+	if b.Policy.Classify(err) == Permanent {
+		return 0, false
+	}
+	b.Attempt++
+	if b.Policy.MaxAttempts > 0 && b.Attempt >= b.Policy.MaxAttempts {
+		return 0, false
+	}
+	return b.Policy.Delay(b.Attempt), true
+}
+
+// Wait 记录一次失败并等待，返回false代表不应再重试（永久错误或次数耗尽）
+func (b *Backoff) Wait(err error) bool {
+	delay, ok := b.Next(err)
+	if ok {
+		time.Sleep(delay)
+	}
+	return ok
+}
+
+// Reset 成功后重置失败次数
+func (b *Backoff) Reset() {
+	b.Attempt = 0
+}
+
+// Do 按策略执行fn直到成功、遇到永久错误或次数耗尽，label用于打印重试日志，为空则不打印
+func Do(p Policy, label string, fn func() error) error {
+	b := &Backoff{Policy: p}
+	for {
+		err := fn()
+		if err == nil {
+			return nil
+		}
+		delay, ok := b.Next(err)
+		if !ok {
+			if p.Classify(err) == Permanent {
+				return err
+			}
+			return errors.Join(ErrExhausted, err)
+		}
+		if label != "" {
+			lg.Print(lg.INFO, label, lg.Yellow, "请求失败，", delay.Round(time.Second).String(), "后进行第", strconv.Itoa(b.Attempt+1), "次尝试：", err.Error())
+		}
+		time.Sleep(delay)
+	}
+}
+
+// DoValue 带返回值的Do
+func DoValue[T any](p Policy, label string, fn func() (T, error)) (T, error) {
+	var result T
+	err := Do(p, label, func() error {
+		var err error
+		result, err = fn()
+		return err
+	})
+	return result, err
+}
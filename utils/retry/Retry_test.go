@@ -0,0 +1,82 @@
+package retry
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestDelay(t *testing.T) {
+	p := Policy{BaseDelay: time.Second, MaxDelay: 5 * time.Second}
+	cases := map[int]time.Duration{1: time.Second, 2: 2 * time.Second, 3: 4 * time.Second, 4: 5 * time.Second, 10: 5 * time.Second}
+	for attempt, want := range cases {
+		if got := p.Delay(attempt); got != want {
+			t.Errorf("Delay(%d) = %v, want %v", attempt, got, want)
+		}
+	}
+	p.Jitter = 0.2
+	for i := 0; i < 100; i++ {
+		if got := p.Delay(2); got < 1600*time.Millisecond || got > 2400*time.Millisecond {
+			t.Fatalf("Delay(2) = %v，超出2s±20%%", got)
+		}
+	}
+}
+
+func TestClassify(t *testing.T) {
+	fatal := errors.New("fatal")
+	p := Policy{Classifier: func(err error) Class {
+		if errors.Is(err, fatal) {
+			return Permanent
+		}
+		return Retryable
+	}}
+	cases := []struct {
+		err  error
+		want Class
+	}{
+		{errors.New("timeout"), Retryable},
+		{fatal, Permanent},
+		{Stop(errors.New("stop")), Permanent},
+	}
+	for _, c := range cases {
+		if got := p.Classify(c.err); got != c.want {
+			t.Errorf("Classify(%v) = %v, want %v", c.err, got, c.want)
+		}
+	}
+	if got := (Policy{}).Classify(errors.New("x")); got != Retryable {
+		t.Errorf("没有分类器时应为Retryable，得到%v", got)
+	}
+}
+
+func TestDo(t *testing.T) {
+	p := Policy{MaxAttempts: 3, BaseDelay: time.Millisecond, MaxDelay: time.Millisecond}
+	calls := 0
+	err := Do(p, "", func() error {
+		calls++
+		if calls < 2 {
+			return errors.New("temporary")
+		}
+		return nil
+	})
+	if err != nil || calls != 2 {
+		t.Errorf("临时错误应重试后成功，err=%v calls=%d", err, calls)
+	}
+
+	calls = 0
+	err = Do(p, "", func() error {
+		calls++
+		return errors.New("temporary")
+	})
+	if !errors.Is(err, ErrExhausted) || calls != 3 {
+		t.Errorf("次数耗尽应返回ErrExhausted，err=%v calls=%d", err, calls)
+	}
+
+	calls = 0
+	err = Do(p, "", func() error {
+		calls++
+		return Stop(errors.New("permanent"))
+	})
+	if errors.Is(err, ErrExhausted) || calls != 1 {
+		t.Errorf("永久错误不应重试，err=%v calls=%d", err, calls)
+	}
+}
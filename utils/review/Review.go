@@ -0,0 +1,120 @@
+package review
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"sync"
+	"time"
+)
+
+// ReviewDir 待审核答卷存放目录，每份作业或考试一个文件
+var ReviewDir = "./assets/review"
+
+// 审核状态
+const (
+	StatusPending = "pending" //等待人工审核
+	StatusApplied = "applied" //已提交
+)
+
+// Item 一份等待人工审核的答卷
+type Item struct {
+	Account  string          `json:"account"`           //账号
+	Platform string          `json:"platform"`          //平台类型，比如XUEXITONG
+	Kind     string          `json:"kind"`              //work为作业，exam为考试
+	Course   string          `json:"course"`            //课程名称
+	Title    string          `json:"title"`             //作业或考试名称
+	Status   string          `json:"status"`            //审核状态
+	Created  time.Time       `json:"created"`           //生成时间
+	Applied  time.Time       `json:"applied,omitempty"` //提交时间
+	Result   string          `json:"result,omitempty"`  //提交后服务器返回信息
+	Payload  json.RawMessage `json:"payload"`           //题目与答案，各平台格式不同，人工修改其中的答案后使用review apply提交
+	Path     string          `json:"-"`                 //文件位置
+}
+
+var reviewMut sync.Mutex
+
+var unsafeReg = regexp.MustCompile(`[^\w\-.]+`)
+
+// ErrPending 同一份答卷已有等待审核的文件，人工可能正在修改，不覆盖
+var ErrPending = errors.New("已有等待人工审核的答卷")
+
+// pathOf 答卷文件位置，同一账号同一份答卷对应同一个文件
+func pathOf(platform, account, id string) string {
+	return filepath.Join(ReviewDir, unsafeReg.ReplaceAllString(platform+"_"+account+"_"+id, "_")+".json")
+}
+
+// Pending 同一账号同一份答卷是否已有等待审核的文件，有则不应再次作答
+func Pending(platform, account, id string) bool {
+	item, err := Load(pathOf(platform, account, id))
+	return err == nil && item.Status == StatusPending
+}
+
+// Save 写入一份待审核答卷，已提交过的会被覆盖，仍在等待审核的返回ErrPending且不覆盖
+func Save(item Item, id string, payload interface{}) (string, error) {
+	reviewMut.Lock()
+	defer reviewMut.Unlock()
+	item.Path = pathOf(item.Platform, item.Account, id)
+	if old, err := Load(item.Path); err == nil && old.Status == StatusPending {
+		return item.Path, ErrPending
+	}
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return "", err
+	}
+	item.Payload = data
+	item.Status = StatusPending
+	item.Created = time.Now()
+	return item.Path, write(item)
+}
+
+func write(item Item) error {
+	data, err := json.MarshalIndent(item, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(filepath.Dir(item.Path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(item.Path, data, 0644)
+}
+
+// Load 读取一份答卷
+func Load(path string) (Item, error) {
+	var item Item
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return item, err
+	}
+	if err = json.Unmarshal(content, &item); err != nil {
+		return item, err
+	}
+	item.Path = path
+	return item, nil
+}
+
+// List 按生成时间列出所有答卷
+func List() []Item {
+	paths, _ := filepath.Glob(filepath.Join(ReviewDir, "*.json"))
+	var items []Item
+	for _, path := range paths {
+		if item, err := Load(path); err == nil {
+			items = append(items, item)
+		}
+	}
+	sort.Slice(items, func(i, j int) bool { return items[i].Created.Before(items[j].Created) })
+	return items
+}
+
+// MarkApplied 标记答卷已提交并记录服务器返回信息
+func MarkApplied(item Item, result string) error {
+	reviewMut.Lock()
+	defer reviewMut.Unlock()
+	item.Status = StatusApplied
+	item.Applied = time.Now()
+	item.Result = result
+	return write(item)
+}
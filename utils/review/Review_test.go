@@ -0,0 +1,42 @@
+package review
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestSavePending(t *testing.T) {
+	old := ReviewDir
+	ReviewDir = t.TempDir()
+	defer func() { ReviewDir = old }()
+
+	item := Item{Account: "a", Platform: "XUEXITONG", Kind: "work"}
+	if Pending(item.Platform, item.Account, "work_1") {
+		t.Fatal("没有文件时不应视为等待审核")
+	}
+	path, err := Save(item, "work_1", []string{"A"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !Pending(item.Platform, item.Account, "work_1") {
+		t.Fatal("保存后应视为等待审核")
+	}
+	//等待审核的答卷不覆盖
+	if _, err = Save(item, "work_1", []string{"B"}); !errors.Is(err, ErrPending) {
+		t.Fatalf("Save err = %v, want ErrPending", err)
+	}
+	saved, _ := Load(path)
+	var answers []string
+	if json.Unmarshal(saved.Payload, &answers); len(answers) != 1 || answers[0] != "A" {
+		t.Fatalf("Payload = %s, 等待审核的答卷被覆盖", saved.Payload)
+	}
+	//已提交的答卷可以重新生成
+	MarkApplied(saved, "ok")
+	if Pending(item.Platform, item.Account, "work_1") {
+		t.Fatal("已提交的答卷不应视为等待审核")
+	}
+	if _, err = Save(item, "work_1", []string{"B"}); err != nil {
+		t.Fatal(err)
+	}
+}
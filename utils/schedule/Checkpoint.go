@@ -0,0 +1,72 @@
+package schedule
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// CheckpointPath 守护模式断点文件位置
+var CheckpointPath = "./assets/daemon/checkpoint.json"
+
+// AccountCheckpoint 账号最近一次运行的断点信息
+type AccountCheckpoint struct {
+	LastStart  time.Time `json:"lastStart"`  //最近一次开始时间
+	LastFinish time.Time `json:"lastFinish"` //最近一次完成时间
+	Finished   bool      `json:"finished"`   //最近一次运行是否已经全部完成
+}
+
+var checkpointMut sync.Mutex
+
+// LoadCheckpoints 读取断点文件，文件不存在时返回空表
+func LoadCheckpoints() map[string]AccountCheckpoint {
+	checkpointMut.Lock()
+	defer checkpointMut.Unlock()
+	return loadCheckpoints()
+}
+
+func loadCheckpoints() map[string]AccountCheckpoint {
+	checkpoints := map[string]AccountCheckpoint{}
+	content, err := os.ReadFile(CheckpointPath)
+	if err != nil {
+		return checkpoints
+	}
+	json.Unmarshal(content, &checkpoints)
+	return checkpoints
+}
+
+// updateCheckpoint 更新某个账号的断点并写回文件
+func updateCheckpoint(account string, update func(cp *AccountCheckpoint)) error {
+	checkpointMut.Lock()
+	defer checkpointMut.Unlock()
+	checkpoints := loadCheckpoints()
+	cp := checkpoints[account]
+	update(&cp)
+	checkpoints[account] = cp
+	data, err := json.MarshalIndent(checkpoints, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(filepath.Dir(CheckpointPath), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(CheckpointPath, data, 0644)
+}
+
+// MarkStart 记录账号开始运行
+func MarkStart(account string) error {
+	return updateCheckpoint(account, func(cp *AccountCheckpoint) {
+		cp.LastStart = time.Now()
+		cp.Finished = false
+	})
+}
+
+// MarkFinish 记录账号运行完毕
+func MarkFinish(account string) error {
+	return updateCheckpoint(account, func(cp *AccountCheckpoint) {
+		cp.LastFinish = time.Now()
+		cp.Finished = true
+	})
+}
@@ -0,0 +1,44 @@
+package schedule
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseCronInvalid(t *testing.T) {
+	for _, expr := range []string{"", "* * * *", "60 * * * *", "* 24 * * *", "* * 0 * *", "* * * 13 *", "* * * * 8", "*/0 * * * *", "5-1 * * * *", "a * * * *"} {
+		if _, err := ParseCron(expr); err == nil {
+			t.Errorf("ParseCron(%q) 应当报错", expr)
+		}
+	}
+}
+
+func TestCronNext(t *testing.T) {
+	//2024-01-01是周一
+	from := time.Date(2024, 1, 1, 10, 30, 0, 0, time.Local)
+	cases := []struct {
+		expr string
+		want time.Time
+	}{
+		{"0 19 * * *", time.Date(2024, 1, 1, 19, 0, 0, 0, time.Local)},
+		{"30 10 * * *", time.Date(2024, 1, 2, 10, 30, 0, 0, time.Local)}, //不含当前分钟
+		{"*/15 * * * *", time.Date(2024, 1, 1, 10, 45, 0, 0, time.Local)},
+		{"0 8-9 * * *", time.Date(2024, 1, 2, 8, 0, 0, 0, time.Local)},
+		{"0 8 * * 0", time.Date(2024, 1, 7, 8, 0, 0, 0, time.Local)},
+		{"0 8 * * 7", time.Date(2024, 1, 7, 8, 0, 0, 0, time.Local)},  //7同样代表周日
+		{"0 0 15 * 3", time.Date(2024, 1, 3, 0, 0, 0, 0, time.Local)}, //日和周满足其一即可
+		{"0 0 1 3 *", time.Date(2024, 3, 1, 0, 0, 0, 0, time.Local)},
+		{"@daily", time.Date(2024, 1, 2, 0, 0, 0, 0, time.Local)},
+		{"@hourly", time.Date(2024, 1, 1, 11, 0, 0, 0, time.Local)},
+		{"0 0 31 2 *", time.Time{}}, //永远不会触发
+	}
+	for _, c := range cases {
+		cron, err := ParseCron(c.expr)
+		if err != nil {
+			t.Fatalf("ParseCron(%q): %v", c.expr, err)
+		}
+		if got := cron.Next(from); !got.Equal(c.want) {
+			t.Errorf("%q.Next = %v, want %v", c.expr, got, c.want)
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package schedule
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// PendingPath 待解锁任务队列文件位置
+var PendingPath = "./assets/daemon/pending.json"
+
+// PendingItem 因未到解锁时间或未开课而暂时跳过的任务
+type PendingItem struct {
+	Account  string    `json:"account"`  //账号
+	Platform string    `json:"platform"` //平台类型
+	Course   string    `json:"course"`   //课程名称
+	Node     string    `json:"node"`     //节点名称，为空代表整个课程
+	Until    time.Time `json:"until"`    //解锁时间
+}
+
+var pendingMut sync.Mutex
+
+func loadPending() []PendingItem {
+	var items []PendingItem
+	content, err := os.ReadFile(PendingPath)
+	if err != nil {
+		return items
+	}
+	json.Unmarshal(content, &items)
+	return items
+}
+
+func savePending(items []PendingItem) error {
+	sort.Slice(items, func(i, j int) bool { return items[i].Until.Before(items[j].Until) })
+	data, err := json.MarshalIndent(items, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(filepath.Dir(PendingPath), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(PendingPath, data, 0644)
+}
+
+// LoadPending 读取待解锁队列，按解锁时间排序
+func LoadPending() []PendingItem {
+	pendingMut.Lock()
+	defer pendingMut.Unlock()
+	return loadPending()
+}
+
+// AddPending 加入待解锁队列，同一账号同一节点只保留一条
+func AddPending(item PendingItem) error {
+	pendingMut.Lock()
+	defer pendingMut.Unlock()
+	items := loadPending()
+	for i, v := range items {
+		if v.Account == item.Account && v.Course == item.Course && v.Node == item.Node {
+			items[i] = item
+			return savePending(items)
+		}
+	}
+	return savePending(append(items, item))
+}
+
+// ClearDuePending 移除账号下已经到达解锁时间的任务，本轮运行若仍未解锁会重新加入
+func ClearDuePending(account string, now time.Time) error {
+	pendingMut.Lock()
+	defer pendingMut.Unlock()
+	items := loadPending()
+	var remain []PendingItem
+	for _, v := range items {
+		if v.Account == account && !v.Until.After(now) {
+			continue
+		}
+		remain = append(remain, v)
+	}
+	if len(remain) == len(items) {
+		return nil
+	}
+	return savePending(remain)
+}
+
+// NextPending 获取账号最早的解锁时间，没有待解锁任务则返回零值
+func NextPending(account string) time.Time {
+	var next time.Time
+	for _, v := range LoadPending() {
+		if v.Account == account && (next.IsZero() || v.Until.Before(next)) {
+			next = v.Until
+		}
+	}
+	return next
+}
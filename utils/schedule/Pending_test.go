@@ -0,0 +1,37 @@
+package schedule
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestPending(t *testing.T) {
+	old := PendingPath
+	PendingPath = filepath.Join(t.TempDir(), "pending.json")
+	defer func() { PendingPath = old }()
+
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.Local)
+	if !NextPending("a").IsZero() {
+		t.Fatal("空队列应返回零值")
+	}
+	AddPending(PendingItem{Account: "a", Course: "c", Node: "n1", Until: now.Add(2 * time.Hour)})
+	AddPending(PendingItem{Account: "a", Course: "c", Node: "n2", Until: now.Add(-time.Hour)})
+	AddPending(PendingItem{Account: "b", Course: "c", Node: "n1", Until: now.Add(-time.Hour)})
+	//同一节点只保留最新一条
+	AddPending(PendingItem{Account: "a", Course: "c", Node: "n1", Until: now.Add(time.Hour)})
+	if got := len(LoadPending()); got != 3 {
+		t.Fatalf("队列长度 = %d, want 3", got)
+	}
+	if got := NextPending("a"); !got.Equal(now.Add(-time.Hour)) {
+		t.Errorf("NextPending = %v", got)
+	}
+
+	ClearDuePending("a", now)
+	if got := NextPending("a"); !got.Equal(now.Add(time.Hour)) {
+		t.Errorf("清理后NextPending = %v, want %v", got, now.Add(time.Hour))
+	}
+	if NextPending("b").IsZero() {
+		t.Error("不应清理其他账号的任务")
+	}
+}
@@ -0,0 +1,131 @@
+package schedule
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	lg "github.com/yatori-dev/yatori-go-core/utils/log"
+)
+
+// Window 允许学习的时间窗口，单位为当天的分钟数，End小于Start时表示跨零点
+type Window struct {
+	Start int
+	End   int
+}
+
+// ParseWindow 解析"08:00-23:00"格式的时间窗口
+func ParseWindow(str string) (Window, error) {
+	parts := strings.Split(strings.ReplaceAll(str, " ", ""), "-")
+	if len(parts) != 2 {
+		return Window{}, fmt.Errorf("时间窗口格式错误，应为HH:MM-HH:MM：%s", str)
+	}
+	start, err := parseClock(parts[0])
+	if err != nil {
+		return Window{}, err
+	}
+	end, err := parseClock(parts[1])
+	if err != nil {
+		return Window{}, err
+	}
+	return Window{Start: start, End: end}, nil
+}
+
+// ParseWindows 批量解析时间窗口
+func ParseWindows(strs []string) ([]Window, error) {
+	var windows []Window
+	for _, str := range strs {
+		w, err := ParseWindow(str)
+		if err != nil {
+			return nil, err
+		}
+		windows = append(windows, w)
+	}
+	return windows, nil
+}
+
+func parseClock(str string) (int, error) {
+	t, err := time.Parse("15:04", str)
+	if err != nil {
+		//允许写24:00表示当天结束
+		if str == "24:00" {
+			return 24 * 60, nil
+		}
+		return 0, fmt.Errorf("时间格式错误，应为HH:MM：%s", str)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// Contains 判断t是否处于窗口内
+func (w Window) Contains(t time.Time) bool {
+	m := t.Hour()*60 + t.Minute()
+	if w.Start <= w.End {
+		return m >= w.Start && m < w.End
+	}
+	return m >= w.Start || m < w.End
+}
+
+// InWindows 判断t是否处于任一窗口内，没有配置窗口则视为不限制
+func InWindows(windows []Window, t time.Time) bool {
+	if len(windows) == 0 {
+		return true
+	}
+	for _, w := range windows {
+		if w.Contains(t) {
+			return true
+		}
+	}
+	return false
+}
+
+// NextOpen 获取t之后最近一次窗口打开的时间，t本身在窗口内则直接返回t
+func NextOpen(windows []Window, t time.Time) time.Time {
+	if InWindows(windows, t) {
+		return t
+	}
+	var next time.Time
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	for _, w := range windows {
+		open := day.Add(time.Duration(w.Start) * time.Minute)
+		if !open.After(t) {
+			open = open.AddDate(0, 0, 1)
+		}
+		if next.IsZero() || open.Before(next) {
+			next = open
+		}
+	}
+	return next
+}
+
+var (
+	windowMap = map[string][]Window{} //账号对应的学习窗口
+	windowMut sync.RWMutex
+)
+
+// SetWindows 设置账号允许学习的时间窗口
+func SetWindows(account string, windows []Window) {
+	windowMut.Lock()
+	defer windowMut.Unlock()
+	windowMap[account] = windows
+}
+
+// GetWindows 获取账号允许学习的时间窗口
+func GetWindows(account string) []Window {
+	windowMut.RLock()
+	defer windowMut.RUnlock()
+	return windowMap[account]
+}
+
+// WaitWindow 在安全提交点调用，如果当前处于学习窗口之外则阻塞到下一个窗口打开
+func WaitWindow(account string) {
+	windows := GetWindows(account)
+	now := time.Now()
+	if InWindows(windows, now) {
+		return
+	}
+	next := NextOpen(windows, now)
+	lg.Print(lg.INFO, "[", lg.Green, account, lg.Default, "] ", lg.Yellow, "当前不在允许学习的时间窗口内，已暂停，将于 ", next.Format("2006-01-02 15:04"), " 继续")
+	time.Sleep(time.Until(next))
+	lg.Print(lg.INFO, "[", lg.Green, account, lg.Default, "] ", lg.Green, "已进入学习时间窗口，继续学习")
+}
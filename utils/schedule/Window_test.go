@@ -0,0 +1,50 @@
+package schedule
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseWindowInvalid(t *testing.T) {
+	for _, str := range []string{"", "08:00", "8点-9点", "08:00-25:00", "08:00-09:00-10:00"} {
+		if _, err := ParseWindow(str); err == nil {
+			t.Errorf("ParseWindow(%q) 应当报错", str)
+		}
+	}
+}
+
+func TestWindows(t *testing.T) {
+	windows, err := ParseWindows([]string{"08:00-12:00", "22:00-02:00", "14:00-24:00"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	at := func(hour, minute int) time.Time {
+		return time.Date(2024, 1, 1, hour, minute, 0, 0, time.Local)
+	}
+	cases := []struct {
+		t        time.Time
+		in       bool
+		nextOpen time.Time
+	}{
+		{at(8, 0), true, at(8, 0)},
+		{at(11, 59), true, at(11, 59)},
+		{at(12, 0), false, at(14, 0)}, //结束时间不包含在窗口内
+		{at(1, 30), true, at(1, 30)},  //跨零点的窗口
+		{at(2, 0), false, at(8, 0)},
+		{at(23, 59), true, at(23, 59)},
+	}
+	for _, c := range cases {
+		if got := InWindows(windows, c.t); got != c.in {
+			t.Errorf("InWindows(%v) = %v, want %v", c.t, got, c.in)
+		}
+		if got := NextOpen(windows, c.t); !got.Equal(c.nextOpen) {
+			t.Errorf("NextOpen(%v) = %v, want %v", c.t, got, c.nextOpen)
+		}
+	}
+	if !InWindows(nil, at(3, 0)) {
+		t.Error("没有配置窗口时应当不限制")
+	}
+	if got := NextOpen([]Window{{Start: 8 * 60, End: 9 * 60}}, at(10, 0)); !got.Equal(at(8, 0).AddDate(0, 0, 1)) {
+		t.Errorf("当天窗口已过时应为第二天打开，得到%v", got)
+	}
+}